@@ -0,0 +1,265 @@
+package api
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/joins"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	AuthHandler    = &authHandler{}
+	ProfileHandler = &profileHandler{}
+	StudentHandler = &studentsHandler{}
+	TeacherHandler = &teachersHandler{}
+)
+
+type (
+	authHandler     struct{}
+	profileHandler  struct{}
+	studentsHandler struct{}
+	teachersHandler struct{}
+)
+
+func (h *authHandler) Register(c *fiber.Ctx) error {
+	req := forms.RegistrateRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	actor, err := account.Register(req, func(token string) string {
+		return c.BaseURL() + "/verify/" + token
+	})
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(newProfileResponse(actor, nil))
+}
+
+func (h *authHandler) Login(c *fiber.Ctx) error {
+	req := forms.LoginRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	actor, err := account.Login(req, c.IP())
+	if err != nil {
+		return fail(c, err)
+	}
+	if actor.TotpEnabled() {
+		challenge, expiresAt, err := account.Challenge(actor)
+		if err != nil {
+			return fail(c, err)
+		}
+		return c.Status(fiber.StatusAccepted).JSON(challengeResponse{Challenge: challenge, ExpiresAt: expiresAt})
+	}
+	tokens, err := account.StartSession(actor, deviceOf(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newTokenResponse(tokens))
+}
+
+// Refresh renews the access token; the refresh token it was given stops working.
+func (h *authHandler) Refresh(c *fiber.Ctx) error {
+	req := forms.RefreshRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	_, tokens, err := account.Refresh(req.RefreshToken, deviceOf(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newTokenResponse(tokens))
+}
+
+// Logout ends the session the refresh token belongs to.
+func (h *authHandler) Logout(c *fiber.Ctx) error {
+	req := forms.RefreshRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	if err := account.SignOut(req.RefreshToken); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RequestPasswordReset mails the reset link if the account exists; the answer is the same when it does not.
+func (h *authHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	req := forms.PasswordResetRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	if err := account.RequestReset(req, func(token string) string {
+		return c.BaseURL() + "/password-reset/" + token
+	}); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+func (h *authHandler) ResetPassword(c *fiber.Ctx) error {
+	req := forms.ResetPasswordRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	if err := account.ResetPassword(c.Params("token"), req); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Verify confirms the email the token was mailed to.
+func (h *authHandler) Verify(c *fiber.Ctx) error {
+	actor, err := account.Verify(c.Params("token"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newProfileResponse(actor, nil))
+}
+
+func (h *profileHandler) Get(c *fiber.Ctx) error {
+	actor, err := currentAccount(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	enrollments, err := joins.EnrollmentsOf(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newProfileResponse(actor, enrollments))
+}
+
+// Update changes the name and email; a new email has to be confirmed again.
+func (h *profileHandler) Update(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.UpdateProfileRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	if err := account.UpdateProfile(actor, req, func(token string) string {
+		return c.BaseURL() + "/verify/" + token
+	}); err != nil {
+		return fail(c, err)
+	}
+	enrollments, err := joins.EnrollmentsOf(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newProfileResponse(actor, enrollments))
+}
+
+// UpdatePassword changes the password and answers with the tokens of a new session, since every session is signed out.
+func (h *profileHandler) UpdatePassword(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.ChangePasswordRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	if err := account.ChangePassword(actor, req); err != nil {
+		return fail(c, err)
+	}
+	tokens, err := account.StartSession(actor, deviceOf(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newTokenResponse(tokens))
+}
+
+// SwitchRole answers with tokens acting in the other role of the user; the session keeps going with them.
+func (h *profileHandler) SwitchRole(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.RoleRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	tokens, err := account.SwitchRole(actor, req, deviceOf(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newTokenResponse(tokens))
+}
+
+// AddRole lets the user teach as well as study, or the other way round. The tokens keep acting in the current role.
+func (h *profileHandler) AddRole(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.RoleRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	if err := account.AddRole(actor, req); err != nil {
+		return fail(c, err)
+	}
+	enrollments, err := joins.EnrollmentsOf(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(newProfileResponse(actor, enrollments))
+}
+
+func (h *profileHandler) Delete(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	if err := account.Delete(actor); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResendVerification mails the link confirming the email again.
+func (h *profileHandler) ResendVerification(c *fiber.Ctx) error {
+	actor, err := currentAccount(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	if err := account.ResendVerification(actor, func(token string) string {
+		return c.BaseURL() + "/verify/" + token
+	}); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+func (h *studentsHandler) GetList(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	students, err := account.Students(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	responses := make([]personResponse, 0, len(students))
+	for i := range students {
+		responses = append(responses, newStudentResponse(&students[i]))
+	}
+	return c.JSON(responses)
+}
+
+func (h *teachersHandler) GetList(c *fiber.Ctx) error {
+	if _, err := currentActor(c); err != nil {
+		return fail(c, err)
+	}
+	teachers, err := account.Teachers()
+	if err != nil {
+		return fail(c, err)
+	}
+	responses := make([]personResponse, 0, len(teachers))
+	for i := range teachers {
+		responses = append(responses, newTeacherListing(&teachers[i]))
+	}
+	return c.JSON(responses)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Prefix is the path the current version of the API is served under.
+const Prefix = "/api/v1"
+
+const problemContentType = "application/problem+json"
+
+// problem is the RFC 7807 problem details body every failed request answers with.
+type problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+func PublicRoutes(router fiber.Router) {
+	router.Post("/auth/register", AuthHandler.Register)
+	router.Post("/auth/login", AuthHandler.Login)
+	router.Post("/auth/login/totp", TotpHandler.Login)
+	router.Post("/auth/password-reset", AuthHandler.RequestPasswordReset)
+	router.Post("/auth/password-reset/:token", AuthHandler.ResetPassword)
+	router.Post("/auth/verify/:token", AuthHandler.Verify)
+	router.Post("/auth/refresh", AuthHandler.Refresh)
+	router.Post("/auth/logout", AuthHandler.Logout)
+}
+
+func AuthorizedRoutes(router fiber.Router) {
+	router.Get("/profile", ProfileHandler.Get)
+	router.Patch("/profile", ProfileHandler.Update)
+	router.Patch("/profile/password", ProfileHandler.UpdatePassword)
+	router.Patch("/profile/role", ProfileHandler.SwitchRole)
+	router.Post("/profile/roles", ProfileHandler.AddRole)
+	router.Delete("/profile", ProfileHandler.Delete)
+	router.Post("/profile/totp", TotpHandler.Create)
+	router.Post("/profile/totp/confirm", TotpHandler.Confirm)
+	router.Post("/profile/totp/recovery-codes", TotpHandler.CreateRecoveryCodes)
+	router.Delete("/profile/totp", TotpHandler.Delete)
+	router.Get("/profile/tokens", ApiTokenHandler.GetList)
+	router.Post("/profile/tokens", ApiTokenHandler.Create)
+	router.Delete("/profile/tokens/:id", ApiTokenHandler.Delete)
+	router.Post("/verification", ProfileHandler.ResendVerification)
+	router.Get("/sessions", SessionHandler.GetList)
+	router.Delete("/sessions", SessionHandler.DeleteAll)
+	router.Delete("/sessions/:id", SessionHandler.Delete)
+
+	router.Get("/joins", JoinHandler.GetList)
+	router.Post("/joins", JoinHandler.Create)
+	router.Patch("/joins/:id", JoinHandler.Update)
+	router.Patch("/enrollments/:id", EnrollmentHandler.Update)
+	router.Post("/invites", InviteHandler.Create)
+	router.Post("/invites/:code", InviteHandler.Join)
+
+	router.Get("/students", StudentHandler.GetList)
+	router.Get("/teachers", TeacherHandler.GetList)
+
+	router.Get("/homeworks", HomeworkHandler.GetList)
+	router.Post("/homeworks", HomeworkHandler.Create)
+	router.Get("/homeworks/:id", HomeworkHandler.Get)
+	router.Patch("/homeworks/:id", HomeworkHandler.Update)
+	router.Delete("/homeworks/:id", HomeworkHandler.Delete)
+	router.Get("/homeworks/:id/files/:fileId", HomeworkHandler.GetFile)
+
+	router.Use(NotFound)
+}
+
+// Unauthorized answers requests without a valid token.
+func Unauthorized(c *fiber.Ctx, err error) error {
+	logrus.WithError(err)
+	return sendProblem(c, fiber.StatusUnauthorized, account.ErrUnauthorized.Error(), nil)
+}
+
+// NotFound answers requests to unknown API routes.
+func NotFound(c *fiber.Ctx) error {
+	return sendProblem(c, fiber.StatusNotFound, "", nil)
+}
+
+// fail answers with the problem err describes.
+func fail(c *fiber.Ctx, err error) error {
+	logrus.WithError(err)
+	utilities.SetRetryAfter(c, err)
+	status := utilities.StatusOf(err)
+	if status == fiber.StatusInternalServerError {
+		return sendProblem(c, status, failure.ErrSomethingWrong.Error(), nil)
+	}
+	return sendProblem(c, status, err.Error(), failure.FieldsOf(err))
+}
+
+// badRequest answers requests whose body cannot be parsed.
+func badRequest(c *fiber.Ctx, err error) error {
+	logrus.WithError(err)
+	return sendProblem(c, fiber.StatusBadRequest, "the request body is malformed", nil)
+}
+
+func sendProblem(c *fiber.Ctx, status int, detail string, fields map[string]string) error {
+	err := c.Status(status).JSON(problem{
+		Type:     "about:blank",
+		Title:    utils.StatusMessage(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.OriginalURL(),
+		Errors:   fields,
+	})
+	c.Set(fiber.HeaderContentType, problemContentType)
+	return err
+}
+
+// currentActor returns the teacher or student the token was issued for once they have confirmed their email
+// and set up two-factor authentication where admins require it.
+// Admins work in the console only.
+func currentActor(c *fiber.Ctx) (*account.Actor, error) {
+	actor, err := currentAccount(c)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Verified() {
+		return nil, account.ErrUnverified
+	}
+	if err := account.CheckTotp(actor); err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+// currentAccount returns the teacher or student the token was issued for, confirmed or not.
+func currentAccount(c *fiber.Ctx) (*account.Actor, error) {
+	jwtPayload, err := utilities.GetJwtPayload(c)
+	if err != nil {
+		return nil, account.ErrUnauthorized
+	}
+	actor, err := account.FromClaims(jwtPayload)
+	if err != nil {
+		return nil, err
+	}
+	if actor.IsAdmin() {
+		return nil, failure.ErrForbidden
+	}
+	return actor, nil
+}
+
+// managing returns the actor find signs in unless the request came with a personal access token: only sessions manage the account.
+func managing(c *fiber.Ctx, find func(*fiber.Ctx) (*account.Actor, error)) (*account.Actor, error) {
+	jwtPayload, err := utilities.GetJwtPayload(c)
+	if err != nil {
+		return nil, account.ErrUnauthorized
+	}
+	if err := account.CheckAccountAccess(jwtPayload); err != nil {
+		return nil, err
+	}
+	return find(c)
+}
+
+func paramId(c *fiber.Ctx, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params(name), 10, 32)
+	if err != nil {
+		return 0, failure.ErrNotFound
+	}
+	return uint(id), nil
+}
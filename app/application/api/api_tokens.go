@@ -0,0 +1,75 @@
+package api
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+)
+
+var ApiTokenHandler = &apiTokensHandler{}
+
+type apiTokensHandler struct{}
+
+// GetList lists the personal access tokens of the user that still work.
+func (h *apiTokensHandler) GetList(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	tokens, err := account.ApiTokens(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	responses := make([]apiTokenResponse, 0, len(tokens))
+	for i := range tokens {
+		responses = append(responses, newApiTokenResponse(&tokens[i]))
+	}
+	return c.JSON(responses)
+}
+
+// Create makes a personal access token acting in the current role. The token is in the answer this once.
+func (h *apiTokensHandler) Create(c *fiber.Ctx) error {
+	actor, err := managing(c, currentActor)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.ApiTokenRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	created, err := account.CreateApiToken(actor, req)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(createdApiTokenResponse{
+		apiTokenResponse: newApiTokenResponse(created.Token),
+		Token:            created.Secret,
+	})
+}
+
+// Delete revokes one of the personal access tokens.
+func (h *apiTokensHandler) Delete(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	if err := account.RevokeApiToken(actor, id); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CheckApiToken signs the request in with the personal access token, which has to be allowed to do what the request does.
+func CheckApiToken(c *fiber.Ctx, token string) error {
+	claims, err := account.CheckApiToken(token, utilities.Writes(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	utilities.SetJwtPayload(c, claims)
+	return c.Next()
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"mime/multipart"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/services/homeworks"
+	"github.com/gofiber/fiber/v2"
+)
+
+var HomeworkHandler = &homeworksHandler{}
+
+type homeworksHandler struct{}
+
+func (h *homeworksHandler) GetList(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	filter := repository.HomeworkFilter{
+		Order: repository.OrderByStatus,
+		Type:  c.Query("type"),
+	}
+	if c.Query("sort") == "urgency" {
+		filter.Order = repository.OrderByUrgency
+	}
+	list, err := homeworks.List(actor, filter)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newHomeworkResponses(list))
+}
+
+func (h *homeworksHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.CreateHomeworkRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	created, err := homeworks.Create(actor, req)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(newHomeworkResponses(created))
+}
+
+func (h *homeworksHandler) Get(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	detail, err := homeworks.Get(actor, homeworkId)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newHomeworkDetailResponse(detail))
+}
+
+// Update accepts JSON or, to attach files to the answer, a multipart form with the files in "files".
+func (h *homeworksHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.UpdateHomeworkRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	var files []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		files = req.ReadMultipart(form)
+	}
+	homework, err := homeworks.Update(actor, homeworkId, req, files)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newHomeworkResponse(homework))
+}
+
+func (h *homeworksHandler) Delete(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	if err := homeworks.Delete(actor, homeworkId); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *homeworksHandler) GetFile(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	fileId, err := paramId(c, "fileId")
+	if err != nil {
+		return fail(c, err)
+	}
+	file, reader, err := homeworks.File(actor, homeworkId, fileId)
+	if err != nil {
+		return fail(c, err)
+	}
+	c.Attachment(file.Name)
+	return c.SendStream(reader)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/services/joins"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	JoinHandler       = &joinsHandler{}
+	EnrollmentHandler = &enrollmentsHandler{}
+	InviteHandler     = &invitesHandler{}
+)
+
+type (
+	joinsHandler       struct{}
+	enrollmentsHandler struct{}
+	invitesHandler     struct{}
+)
+
+func (h *joinsHandler) GetList(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	requests, err := joins.PendingOf(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	responses := make([]joinRequestResponse, 0, len(requests))
+	for i := range requests {
+		responses = append(responses, newJoinRequestResponse(&requests[i]))
+	}
+	return c.JSON(responses)
+}
+
+func (h *joinsHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.CreateJoinRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	request, err := joins.Request(actor, req)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(newJoinRequestResponse(request))
+}
+
+func (h *joinsHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.AnswerJoinRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	request, err := joins.Answer(actor, id, req)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newJoinRequestResponse(request))
+}
+
+func (h *enrollmentsHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.UpdateEnrollmentRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	enrollment, err := joins.UpdateEnrollment(actor, id, req)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newEnrollmentResponse(enrollment))
+}
+
+func (h *invitesHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	invite, err := joins.Invite(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(inviteResponse{
+		Code: invite.Code,
+		Link: c.BaseURL() + "/invites/" + invite.Code,
+	})
+}
+
+func (h *invitesHandler) Join(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return fail(c, err)
+	}
+	if _, err := joins.Join(actor, c.Params("code")); err != nil {
+		return fail(c, err)
+	}
+	enrollments, err := joins.EnrollmentsOf(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newProfileResponse(actor, enrollments))
+}
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/deadline"
+	"github.com/MikhailR1337/task-sync-x/app/services/homeworks"
+	"github.com/MikhailR1337/task-sync-x/app/services/lifecycle"
+)
+
+type (
+	tokenResponse struct {
+		Token            string    `json:"token"`
+		ExpiresAt        time.Time `json:"expiresAt"`
+		RefreshToken     string    `json:"refreshToken"`
+		RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+	}
+	// challengeResponse asks for the second sign in step with POST /auth/login/totp.
+	challengeResponse struct {
+		Challenge string    `json:"challenge"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	totpSetupResponse struct {
+		Secret string `json:"secret"`
+		Uri    string `json:"uri"`
+	}
+	recoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	sessionResponse struct {
+		Id         uint      `json:"id"`
+		UserAgent  string    `json:"userAgent"`
+		Ip         string    `json:"ip"`
+		Current    bool      `json:"current"`
+		CreatedAt  time.Time `json:"createdAt"`
+		LastUsedAt time.Time `json:"lastUsedAt"`
+		ExpiresAt  time.Time `json:"expiresAt"`
+	}
+	apiTokenResponse struct {
+		Id         uint       `json:"id"`
+		Name       string     `json:"name"`
+		Role       string     `json:"role"`
+		Scope      string     `json:"scope"`
+		CreatedAt  time.Time  `json:"createdAt"`
+		LastUsedAt *time.Time `json:"lastUsedAt"`
+		ExpiresAt  time.Time  `json:"expiresAt"`
+	}
+	createdApiTokenResponse struct {
+		apiTokenResponse
+		// Token is shown this once; only its hash is kept.
+		Token string `json:"token"`
+	}
+	personResponse struct {
+		Id   uint   `json:"id"`
+		Name string `json:"name"`
+		// Email is left out where the person does not work with the one asking, like in the list of all teachers.
+		Email string `json:"email,omitempty"`
+	}
+	profileResponse struct {
+		personResponse
+		Role        string               `json:"role"`
+		Roles       []string             `json:"roles"`
+		Verified    bool                 `json:"verified"`
+		TotpEnabled bool                 `json:"totpEnabled"`
+		Enrollments []enrollmentResponse `json:"enrollments"`
+	}
+	enrollmentResponse struct {
+		Id        uint            `json:"id"`
+		Status    string          `json:"status"`
+		StudentId uint            `json:"studentId"`
+		TeacherId uint            `json:"teacherId"`
+		Student   *personResponse `json:"student,omitempty"`
+		Teacher   *personResponse `json:"teacher,omitempty"`
+		StartsAt  time.Time       `json:"startsAt"`
+		EndsAt    *time.Time      `json:"endsAt"`
+	}
+	homeworkResponse struct {
+		Id            uint            `json:"id"`
+		Name          string          `json:"name"`
+		Description   string          `json:"description"`
+		CurrentPoints float64         `json:"currentPoints"`
+		MaxPoints     float64         `json:"maxPoints"`
+		Type          string          `json:"type"`
+		Status        string          `json:"status"`
+		TeacherId     uint            `json:"teacherId"`
+		StudentId     uint            `json:"studentId"`
+		RubricId      *uint           `json:"rubricId"`
+		DueAt         *time.Time      `json:"dueAt"`
+		SubmittedAt   *time.Time      `json:"submittedAt"`
+		LatePenalty   uint8           `json:"latePenalty"`
+		Deadline      deadline.Status `json:"deadline,omitempty"`
+		CreatedAt     time.Time       `json:"createdAt"`
+		UpdatedAt     time.Time       `json:"updatedAt"`
+	}
+	homeworkDetailResponse struct {
+		homeworkResponse
+		Teacher  personResponse    `json:"teacher"`
+		Student  personResponse    `json:"student"`
+		Rubric   *rubricResponse   `json:"rubric"`
+		Attempts []attemptResponse `json:"attempts"`
+		Comments []commentResponse `json:"comments"`
+		Statuses []lifecycle.State `json:"statuses"`
+	}
+	rubricResponse struct {
+		Id       uint                `json:"id"`
+		Name     string              `json:"name"`
+		Criteria []criterionResponse `json:"criteria"`
+	}
+	criterionResponse struct {
+		Id        uint    `json:"id"`
+		Name      string  `json:"name"`
+		MaxPoints float64 `json:"maxPoints"`
+	}
+	attemptResponse struct {
+		Number       uint            `json:"number"`
+		Status       string          `json:"status"`
+		Points       *float64        `json:"points"`
+		Feedback     string          `json:"feedback"`
+		ReturnReason string          `json:"returnReason"`
+		Answer       string          `json:"answer"`
+		Files        []fileResponse  `json:"files"`
+		Scores       []scoreResponse `json:"scores"`
+		SubmittedAt  time.Time       `json:"submittedAt"`
+		ReviewedAt   *time.Time      `json:"reviewedAt"`
+	}
+	fileResponse struct {
+		Id          uint   `json:"id"`
+		Name        string `json:"name"`
+		Size        int64  `json:"size"`
+		ContentType string `json:"contentType"`
+		Url         string `json:"url"`
+	}
+	scoreResponse struct {
+		CriterionId uint    `json:"criterionId"`
+		Name        string  `json:"name"`
+		Points      float64 `json:"points"`
+		MaxPoints   float64 `json:"maxPoints"`
+	}
+	joinRequestResponse struct {
+		Id        uint            `json:"id"`
+		Status    string          `json:"status"`
+		StudentId uint            `json:"studentId"`
+		TeacherId uint            `json:"teacherId"`
+		Student   *personResponse `json:"student,omitempty"`
+		Teacher   *personResponse `json:"teacher,omitempty"`
+		CreatedAt time.Time       `json:"createdAt"`
+	}
+	inviteResponse struct {
+		Code string `json:"code"`
+		Link string `json:"link"`
+	}
+	commentResponse struct {
+		Id         uint      `json:"id"`
+		AuthorId   uint      `json:"authorId"`
+		AuthorRole string    `json:"authorRole"`
+		Body       string    `json:"body"`
+		CreatedAt  time.Time `json:"createdAt"`
+	}
+)
+
+func newTeacherResponse(teacher *models.Teacher) personResponse {
+	return personResponse{Id: teacher.Id, Name: teacher.Name, Email: teacher.Email}
+}
+
+// newTeacherListing describes a teacher to anyone choosing one, without their email.
+func newTeacherListing(teacher *models.Teacher) personResponse {
+	return personResponse{Id: teacher.Id, Name: teacher.Name}
+}
+
+func newStudentResponse(student *models.Student) personResponse {
+	return personResponse{Id: student.Id, Name: student.Name, Email: student.Email}
+}
+
+func newTokenResponse(tokens *account.Tokens) tokenResponse {
+	return tokenResponse{
+		Token:            tokens.Access,
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.Refresh,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
+
+// newSessionResponse describes the session, marking the one the actor signed in with.
+func newSessionResponse(session *models.Session, actor *account.Actor) sessionResponse {
+	return sessionResponse{
+		Id:         session.Id,
+		UserAgent:  session.UserAgent,
+		Ip:         session.Ip,
+		Current:    session.Id == actor.SessionId,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
+
+func newApiTokenResponse(token *models.ApiToken) apiTokenResponse {
+	return apiTokenResponse{
+		Id:         token.Id,
+		Name:       token.Name,
+		Role:       token.Role,
+		Scope:      token.Scope,
+		CreatedAt:  token.CreatedAt,
+		LastUsedAt: token.LastUsedAt,
+		ExpiresAt:  token.ExpiresAt,
+	}
+}
+
+func newProfileResponse(actor *account.Actor, enrollments []models.Enrollment) profileResponse {
+	profile := profileResponse{
+		personResponse: personResponse{Id: actor.Id(), Name: actor.Name(), Email: actor.Email()},
+		Role:           actor.Role,
+		Roles:          actor.Roles(),
+		Verified:       actor.Verified(),
+		TotpEnabled:    actor.TotpEnabled(),
+		Enrollments:    make([]enrollmentResponse, 0, len(enrollments)),
+	}
+	for i := range enrollments {
+		profile.Enrollments = append(profile.Enrollments, newEnrollmentResponse(&enrollments[i]))
+	}
+	return profile
+}
+
+// newEnrollmentResponse describes the enrollment with the accounts that were loaded with it.
+func newEnrollmentResponse(enrollment *models.Enrollment) enrollmentResponse {
+	response := enrollmentResponse{
+		Id:        enrollment.Id,
+		Status:    enrollment.Status,
+		StudentId: enrollment.StudentId,
+		TeacherId: enrollment.TeacherId,
+		StartsAt:  enrollment.StartsAt,
+		EndsAt:    enrollment.EndsAt,
+	}
+	if enrollment.Student.Id != 0 {
+		student := newStudentResponse(&enrollment.Student)
+		response.Student = &student
+	}
+	if enrollment.Teacher.Id != 0 {
+		teacher := newTeacherResponse(&enrollment.Teacher)
+		response.Teacher = &teacher
+	}
+	return response
+}
+
+// newJoinRequestResponse describes the request with the accounts that were loaded with it.
+func newJoinRequestResponse(request *models.JoinRequest) joinRequestResponse {
+	response := joinRequestResponse{
+		Id:        request.Id,
+		Status:    request.Status,
+		StudentId: request.StudentId,
+		TeacherId: request.TeacherId,
+		CreatedAt: request.CreatedAt,
+	}
+	if request.Student.Id != 0 {
+		student := newStudentResponse(&request.Student)
+		response.Student = &student
+	}
+	if request.Teacher.Id != 0 {
+		teacher := newTeacherResponse(&request.Teacher)
+		response.Teacher = &teacher
+	}
+	return response
+}
+
+func newHomeworkResponse(homework *models.Homework) homeworkResponse {
+	return homeworkResponse{
+		Id:            homework.Id,
+		Name:          homework.Name,
+		Description:   homework.Description,
+		CurrentPoints: homework.CurrentPoints,
+		MaxPoints:     homework.MaxPoints,
+		Type:          homework.Type,
+		Status:        homework.Status,
+		TeacherId:     homework.TeacherId,
+		StudentId:     homework.StudentId,
+		RubricId:      homework.RubricId,
+		DueAt:         homework.DueAt,
+		SubmittedAt:   homework.SubmittedAt,
+		LatePenalty:   homework.LatePenalty,
+		Deadline:      deadline.Check(homework.DueAt, homework.SubmittedAt, time.Now()),
+		CreatedAt:     homework.CreatedAt,
+		UpdatedAt:     homework.UpdatedAt,
+	}
+}
+
+func newHomeworkResponses(homeworks []models.Homework) []homeworkResponse {
+	responses := make([]homeworkResponse, 0, len(homeworks))
+	for i := range homeworks {
+		responses = append(responses, newHomeworkResponse(&homeworks[i]))
+	}
+	return responses
+}
+
+func newHomeworkDetailResponse(detail *homeworks.Detail) homeworkDetailResponse {
+	response := homeworkDetailResponse{
+		homeworkResponse: newHomeworkResponse(detail.Homework),
+		Teacher:          newTeacherResponse(detail.Teacher),
+		Student:          newStudentResponse(detail.Student),
+		Attempts:         make([]attemptResponse, 0, len(detail.Attempts)),
+		Comments:         make([]commentResponse, 0, len(detail.Comments)),
+		Statuses:         detail.Statuses,
+	}
+	if response.Statuses == nil {
+		response.Statuses = []lifecycle.State{}
+	}
+	if detail.Rubric != nil {
+		rubric := &rubricResponse{Id: detail.Rubric.Id, Name: detail.Rubric.Name, Criteria: []criterionResponse{}}
+		for _, criterion := range detail.Rubric.Criteria {
+			rubric.Criteria = append(rubric.Criteria, criterionResponse{
+				Id:        criterion.Id,
+				Name:      criterion.Name,
+				MaxPoints: criterion.MaxPoints,
+			})
+		}
+		response.Rubric = rubric
+	}
+	for _, attempt := range detail.Attempts {
+		response.Attempts = append(response.Attempts, newAttemptResponse(detail.Homework.Id, attempt))
+	}
+	for _, comment := range detail.Comments {
+		response.Comments = append(response.Comments, commentResponse{
+			Id:         comment.Id,
+			AuthorId:   comment.AuthorId,
+			AuthorRole: comment.AuthorRole,
+			Body:       comment.Body,
+			CreatedAt:  comment.CreatedAt,
+		})
+	}
+	return response
+}
+
+func newAttemptResponse(homeworkId uint, attempt models.Attempt) attemptResponse {
+	response := attemptResponse{
+		Number:       attempt.Number,
+		Status:       attempt.Status,
+		Points:       attempt.Points,
+		Feedback:     attempt.Feedback,
+		ReturnReason: attempt.ReturnReason,
+		Files:        []fileResponse{},
+		Scores:       []scoreResponse{},
+		SubmittedAt:  attempt.SubmittedAt,
+		ReviewedAt:   attempt.ReviewedAt,
+	}
+	if attempt.Submission != nil {
+		response.Answer = attempt.Submission.Answer
+		for _, file := range attempt.Submission.Files {
+			response.Files = append(response.Files, fileResponse{
+				Id:          file.Id,
+				Name:        file.Name,
+				Size:        file.Size,
+				ContentType: file.ContentType,
+				Url:         fmt.Sprintf("%s/homeworks/%d/files/%d", Prefix, homeworkId, file.Id),
+			})
+		}
+	}
+	for _, score := range attempt.Scores {
+		response.Scores = append(response.Scores, scoreResponse{
+			CriterionId: score.CriterionId,
+			Name:        score.Name,
+			Points:      score.Points,
+			MaxPoints:   score.MaxPoints,
+		})
+	}
+	return response
+}
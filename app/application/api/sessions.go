@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+)
+
+var SessionHandler = &sessionsHandler{}
+
+type sessionsHandler struct{}
+
+// GetList lists the devices the account is signed in on.
+func (h *sessionsHandler) GetList(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	sessions, err := account.Sessions(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	responses := make([]sessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, newSessionResponse(&sessions[i], actor))
+	}
+	return c.JSON(responses)
+}
+
+// Delete signs the account out on one device.
+func (h *sessionsHandler) Delete(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return fail(c, err)
+	}
+	if err := account.RevokeSession(actor, id); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteAll signs the account out everywhere.
+func (h *sessionsHandler) DeleteAll(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	if err := account.SignOutEverywhere(actor); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CheckSession lets the request in while the session of its token has not been revoked.
+func CheckSession(c *fiber.Ctx) error {
+	claims, err := utilities.GetJwtPayload(c)
+	if err == nil {
+		err = account.CheckSession(claims)
+	}
+	if err != nil {
+		return Unauthorized(c, err)
+	}
+	return c.Next()
+}
+
+// deviceOf describes where the request comes from.
+func deviceOf(c *fiber.Ctx) account.Device {
+	return account.Device{UserAgent: c.Get(fiber.HeaderUserAgent), Ip: c.IP()}
+}
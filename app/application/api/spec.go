@@ -0,0 +1,187 @@
+package api
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	tokenAuth = []string{openapi.BearerAuth, openapi.CookieAuth, openapi.ApiTokenAuth}
+	// sessionAuth is for managing the account, which personal access tokens cannot do.
+	sessionAuth = []string{openapi.BearerAuth, openapi.CookieAuth}
+)
+
+// problems describes the problem details the operation can fail with.
+func problems(statuses ...int) []openapi.Response {
+	descriptions := map[int]string{
+		fiber.StatusBadRequest:          "The body is malformed",
+		fiber.StatusUnauthorized:        "The token is missing, expired or the account is gone",
+		fiber.StatusForbidden:           "The role cannot do this, the email is not confirmed yet or a personal access token cannot manage the account",
+		fiber.StatusNotFound:            "The resource does not exist",
+		fiber.StatusConflict:            "The request conflicts with the current state",
+		fiber.StatusUnprocessableEntity: "The data is invalid",
+		fiber.StatusTooManyRequests:     "Too many requests, try again later",
+	}
+	responses := make([]openapi.Response, 0, len(statuses))
+	for _, status := range statuses {
+		response := openapi.JSON(status, descriptions[status], problem{})
+		response.Content = problemContentType
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func responses(success openapi.Response, statuses ...int) []openapi.Response {
+	return append([]openapi.Response{success}, problems(statuses...)...)
+}
+
+// Operations describes the routes registered by PublicRoutes and AuthorizedRoutes.
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/register", Tag: "api", Summary: "Register a user who teaches or studies; the other role can be added later",
+		Body: forms.RegistrateRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusCreated, "The profile of the new account", profileResponse{}),
+			fiber.StatusBadRequest, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/login", Tag: "api", Summary: "Start a session acting as a teacher when the user can, as a student otherwise",
+		Body: forms.LoginRequest{},
+		Responses: append(responses(openapi.JSON(fiber.StatusOK, "A short-lived bearer token and the refresh token of the session", tokenResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusUnprocessableEntity, fiber.StatusTooManyRequests),
+			openapi.JSON(fiber.StatusAccepted, "Users with two-factor authentication finish signing in with the challenge and a code", challengeResponse{}))},
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/login/totp", Tag: "api", Summary: "Finish signing in with a code of the authenticator app or a recovery code",
+		Body: forms.TotpLoginRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "A short-lived bearer token and the refresh token of the session", tokenResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusUnprocessableEntity, fiber.StatusTooManyRequests)},
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/password-reset", Tag: "api", Summary: "Mail a one-time password reset link",
+		Body: forms.PasswordResetRequest{},
+		Responses: responses(openapi.Empty(fiber.StatusAccepted, "The link is mailed if the account exists"),
+			fiber.StatusBadRequest, fiber.StatusUnprocessableEntity, fiber.StatusTooManyRequests)},
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/password-reset/:token", Tag: "api", Summary: "Set a new password with the mailed token",
+		Body: forms.ResetPasswordRequest{},
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "The password is changed"),
+			fiber.StatusBadRequest, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/refresh", Tag: "api", Summary: "Trade the refresh token for new tokens; the old refresh token stops working",
+		Body: forms.RefreshRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "A new bearer token and refresh token", tokenResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized)},
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/logout", Tag: "api", Summary: "End the session of the refresh token",
+		Body: forms.RefreshRequest{},
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "Signed out"),
+			fiber.StatusBadRequest)},
+	{Method: fiber.MethodPost, Path: Prefix + "/auth/verify/:token", Tag: "api", Summary: "Confirm the email with the mailed token",
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The profile of the confirmed account", profileResponse{}),
+			fiber.StatusUnprocessableEntity)},
+
+	{Method: fiber.MethodGet, Path: Prefix + "/profile", Tag: "api", Summary: "The signed in account", Security: tokenAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The profile", profileResponse{}),
+			fiber.StatusUnauthorized)},
+	{Method: fiber.MethodPatch, Path: Prefix + "/profile", Tag: "api", Summary: "Change the name and email; a new email needs the current password, has to be confirmed again and signs out the other sessions", Security: sessionAuth,
+		Body: forms.UpdateProfileRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The profile", profileResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPatch, Path: Prefix + "/profile/password", Tag: "api", Summary: "Change the password; every session is signed out", Security: sessionAuth,
+		Body: forms.ChangePasswordRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The tokens of a new session", tokenResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPatch, Path: Prefix + "/profile/role", Tag: "api", Summary: "Act in the other role of the user; the session goes on with the new tokens", Security: sessionAuth,
+		Body: forms.RoleRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The tokens acting in the role", tokenResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPost, Path: Prefix + "/profile/roles", Tag: "api", Summary: "Add the other role to the user, who goes on acting in the current one", Security: sessionAuth,
+		Body: forms.RoleRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusCreated, "The profile with the new role", profileResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPost, Path: Prefix + "/profile/totp", Tag: "api", Summary: "Start setting up two-factor authentication with a new secret", Security: sessionAuth,
+		Responses: responses(openapi.JSON(fiber.StatusCreated, "The secret and its provisioning URI for the authenticator app", totpSetupResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusConflict)},
+	{Method: fiber.MethodPost, Path: Prefix + "/profile/totp/confirm", Tag: "api", Summary: "Turn two-factor authentication on with a code of the authenticator app", Security: sessionAuth,
+		Body: forms.TotpCodeRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The recovery codes, returned this once", recoveryCodesResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPost, Path: Prefix + "/profile/totp/recovery-codes", Tag: "api", Summary: "Replace the recovery codes", Security: sessionAuth,
+		Body: forms.TotpCodeRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The new recovery codes, returned this once", recoveryCodesResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodDelete, Path: Prefix + "/profile/totp", Tag: "api", Summary: "Turn two-factor authentication off unless admins require it", Security: sessionAuth,
+		Body: forms.TotpCodeRequest{},
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "Turned off"),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodGet, Path: Prefix + "/profile/tokens", Tag: "api", Summary: "The personal access tokens of the user that still work", Security: sessionAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The tokens", []apiTokenResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodPost, Path: Prefix + "/profile/tokens", Tag: "api", Summary: "Make a personal access token acting in the current role; read tokens cannot change things", Security: sessionAuth,
+		Body: forms.ApiTokenRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusCreated, "The token, shown this once", createdApiTokenResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodDelete, Path: Prefix + "/profile/tokens/:id", Tag: "api", Summary: "Revoke a personal access token", Security: sessionAuth,
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "Revoked"),
+			fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound)},
+	{Method: fiber.MethodDelete, Path: Prefix + "/profile", Tag: "api", Summary: "Delete the account with its homework", Security: sessionAuth,
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "Deleted"),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodPost, Path: Prefix + "/verification", Tag: "api", Summary: "Mail the link confirming the email again", Security: tokenAuth,
+		Responses: responses(openapi.Empty(fiber.StatusAccepted, "The link is mailed"),
+			fiber.StatusUnauthorized, fiber.StatusConflict)},
+	{Method: fiber.MethodGet, Path: Prefix + "/sessions", Tag: "api", Summary: "The devices the account is signed in on", Security: sessionAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The active sessions", []sessionResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodDelete, Path: Prefix + "/sessions", Tag: "api", Summary: "Sign out everywhere", Security: sessionAuth,
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "Every session is signed out"),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodDelete, Path: Prefix + "/sessions/:id", Tag: "api", Summary: "Sign out on one device", Security: sessionAuth,
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "Signed out"),
+			fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound)},
+
+	{Method: fiber.MethodGet, Path: Prefix + "/joins", Tag: "api", Summary: "Join requests waiting for the teacher's answer or sent by the student", Security: tokenAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The pending requests", []joinRequestResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodPost, Path: Prefix + "/joins", Tag: "api", Summary: "Ask a teacher to take the student into the class", Security: tokenAuth,
+		Body: forms.CreateJoinRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusCreated, "The pending request", joinRequestResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPatch, Path: Prefix + "/joins/:id", Tag: "api", Summary: "Accept or reject a join request", Security: tokenAuth,
+		Body: forms.AnswerJoinRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The answered request", joinRequestResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPatch, Path: Prefix + "/enrollments/:id", Tag: "api", Summary: "Finish the student's studies with the teacher or start them again", Security: tokenAuth,
+		Body: forms.UpdateEnrollmentRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The enrollment", enrollmentResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodPost, Path: Prefix + "/invites", Tag: "api", Summary: "Make a new invite code; the previous one stops working", Security: tokenAuth,
+		Responses: responses(openapi.JSON(fiber.StatusCreated, "The invite", inviteResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodPost, Path: Prefix + "/invites/:code", Tag: "api", Summary: "Join the class of the teacher who shared the code", Security: tokenAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The profile", profileResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound)},
+
+	{Method: fiber.MethodGet, Path: Prefix + "/students", Tag: "api", Summary: "The teacher's students", Security: tokenAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The students", []personResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodGet, Path: Prefix + "/teachers", Tag: "api", Summary: "All teachers", Security: tokenAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The teachers, without their emails", []personResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+
+	{Method: fiber.MethodGet, Path: Prefix + "/homeworks", Tag: "api", Summary: "The homework the teacher gives or the student does", Security: tokenAuth,
+		Query: []openapi.Parameter{
+			{Name: "sort", Description: "urgency to list unfinished homework by due date first"},
+			{Name: "type", Description: "List only homework of this type"},
+		},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The homework", []homeworkResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden)},
+	{Method: fiber.MethodPost, Path: Prefix + "/homeworks", Tag: "api", Summary: "Give homework to students or a group", Security: tokenAuth,
+		Body: forms.CreateHomeworkRequest{},
+		Responses: responses(openapi.JSON(fiber.StatusCreated, "The homework of every student", []homeworkResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodGet, Path: Prefix + "/homeworks/:id", Tag: "api", Summary: "Homework with its attempts and comments", Security: tokenAuth,
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The homework", homeworkDetailResponse{}),
+			fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound)},
+	{Method: fiber.MethodPatch, Path: Prefix + "/homeworks/:id", Tag: "api", Summary: "Change the homework status", Security: tokenAuth,
+		Body: forms.UpdateHomeworkRequest{}, Files: []string{"files"},
+		Responses: responses(openapi.JSON(fiber.StatusOK, "The homework", homeworkResponse{}),
+			fiber.StatusBadRequest, fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict, fiber.StatusUnprocessableEntity)},
+	{Method: fiber.MethodDelete, Path: Prefix + "/homeworks/:id", Tag: "api", Summary: "Delete homework", Security: tokenAuth,
+		Responses: responses(openapi.Empty(fiber.StatusNoContent, "Deleted"),
+			fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound)},
+	{Method: fiber.MethodGet, Path: Prefix + "/homeworks/:id/files/:fileId", Tag: "api", Summary: "Download a submitted file", Security: tokenAuth,
+		Responses: responses(openapi.File("The file"),
+			fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusNotFound)},
+}
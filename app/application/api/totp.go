@@ -0,0 +1,87 @@
+package api
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+)
+
+var TotpHandler = &totpHandler{}
+
+type totpHandler struct{}
+
+// Login finishes signing in with the challenge the password step answered with and a code.
+func (h *totpHandler) Login(c *fiber.Ctx) error {
+	req := forms.TotpLoginRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	actor, err := account.CompleteLogin(req, c.IP())
+	if err != nil {
+		return fail(c, err)
+	}
+	tokens, err := account.StartSession(actor, deviceOf(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(newTokenResponse(tokens))
+}
+
+func (h *totpHandler) Create(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	setup, err := account.StartTotp(actor)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(totpSetupResponse{Secret: setup.Secret, Uri: setup.Uri})
+}
+
+func (h *totpHandler) Confirm(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.TotpCodeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	codes, err := account.EnableTotp(actor, req)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *totpHandler) CreateRecoveryCodes(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.TotpCodeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	codes, err := account.NewRecoveryCodes(actor, req)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *totpHandler) Delete(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return fail(c, err)
+	}
+	req := forms.TotpCodeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return badRequest(c, err)
+	}
+	if err := account.DisableTotp(actor, req); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
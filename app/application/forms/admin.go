@@ -0,0 +1,9 @@
+package forms
+
+type AccountStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active deactivated"`
+}
+
+type ReassignRequest struct {
+	Teacher string `json:"teacher" validate:"required,numeric"`
+}
@@ -0,0 +1,5 @@
+package forms
+
+type CreateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
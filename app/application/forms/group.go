@@ -0,0 +1,9 @@
+package forms
+
+type CreateGroupRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type GroupStudentRequest struct {
+	Student string `json:"student" validate:"required,numeric"`
+}
@@ -1,20 +1,45 @@
 package forms
 
+import (
+	"mime/multipart"
+	"strings"
+)
+
 type CreateHomeworkRequest struct {
-	Name          string `json:"name" validate:"required,max=100"`
-	Description   string `json:"description" validate:"required,max=500"`
-	CurrentPoints string `json:"currentPoints" validate:"required,max=2"`
-	MaxPoints     string `json:"maxPoints" validate:"required,max=2"`
-	Type          string `json:"type" validate:"required,oneof=listening reading"`
-	Status        string `json:"status" validate:"required,oneof=new processing finished checked"`
-	Student       string `json:"student" validate:"required"`
+	Name          string   `json:"name" validate:"required,max=100"`
+	Description   string   `json:"description" validate:"required,max=500"`
+	CurrentPoints string   `json:"currentPoints" validate:"omitempty,max=8"`
+	MaxPoints     string   `json:"maxPoints" validate:"omitempty,max=8"`
+	Type          string   `json:"type" validate:"required,max=50"`
+	Students      []string `json:"students" validate:"required_without=Group,dive,numeric"`
+	Group         string   `json:"group" validate:"omitempty,numeric"`
+	Rubric        string   `json:"rubric" validate:"omitempty,numeric"`
+	DueAt         string   `json:"dueAt" validate:"omitempty,datetime=2006-01-02T15:04"`
+	LatePenalty   string   `json:"latePenalty" validate:"omitempty,numeric,max=3"`
 }
 
-type UpdateHomeworkStudentRequest struct {
-	Status string `json:"status" validate:"required,oneof=processing finished"`
+// UpdateHomeworkRequest moves homework to another status. Teachers grade it with
+// currentPoints or with criteria points keyed by criterion id, students finish it with an answer.
+type UpdateHomeworkRequest struct {
+	Status        string            `json:"status" validate:"required,oneof=processing finished checked returned"`
+	Answer        string            `json:"answer" validate:"max=5000"`
+	CurrentPoints string            `json:"currentPoints" validate:"omitempty,max=8"`
+	Criteria      map[string]string `json:"criteria"`
+	Feedback      string            `json:"feedback" validate:"max=2000"`
+	Reason        string            `json:"reason" validate:"required_if=Status returned,max=2000"`
 }
 
-type UpdateHomeworkTeacherRequest struct {
-	Status        string `json:"status" validate:"required,oneof=checked"`
-	CurrentPoints string `json:"currentPoints" validate:"required,max=2"`
+// ReadMultipart reads the uploaded files of the multipart form and, unless the body had them already,
+// the rubric criterion points sent as criterion_<id> fields.
+func (r *UpdateHomeworkRequest) ReadMultipart(form *multipart.Form) []*multipart.FileHeader {
+	if r.Criteria == nil {
+		criteria := map[string]string{}
+		for key, values := range form.Value {
+			if id, ok := strings.CutPrefix(key, "criterion_"); ok && len(values) > 0 {
+				criteria[id] = values[0]
+			}
+		}
+		r.Criteria = criteria
+	}
+	return form.File["files"]
 }
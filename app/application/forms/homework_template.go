@@ -0,0 +1,16 @@
+package forms
+
+type HomeworkTemplateRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"required,max=500"`
+	MaxPoints   string `json:"maxPoints" validate:"required,max=8"`
+	Type        string `json:"type" validate:"required,max=50"`
+}
+
+// HomeworkTemplate is the shape templates are exported and imported in.
+type HomeworkTemplate struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description string  `json:"description" validate:"required,max=500"`
+	MaxPoints   float64 `json:"maxPoints" validate:"required,gt=0,lte=99999.99"`
+	Type        string  `json:"type" validate:"required,max=50"`
+}
@@ -0,0 +1,7 @@
+package forms
+
+type HomeworkTypeRequest struct {
+	Name             string `json:"name" validate:"required,max=50"`
+	Color            string `json:"color" validate:"required,hexcolor"`
+	DefaultMaxPoints string `json:"defaultMaxPoints" validate:"required,max=8"`
+}
@@ -0,0 +1,13 @@
+package forms
+
+type CreateJoinRequest struct {
+	Teacher string `json:"teacher" validate:"required,numeric"`
+}
+
+type AnswerJoinRequest struct {
+	Status string `json:"status" validate:"required,oneof=accepted rejected"`
+}
+
+type UpdateEnrollmentRequest struct {
+	Status string `json:"status" validate:"required,oneof=active finished"`
+}
@@ -3,5 +3,8 @@ package forms
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,max=30"`
-	Role     string `json:"role" validate:"required,oneof=student teacher"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
 }
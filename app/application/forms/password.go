@@ -0,0 +1,9 @@
+package forms
+
+type PasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" validate:"required,max=30"`
+}
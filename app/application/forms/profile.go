@@ -0,0 +1,25 @@
+package forms
+
+// UpdateProfileRequest changes the name and email. A new email needs the current password too.
+type UpdateProfileRequest struct {
+	Name            string `json:"name" validate:"required,max=100"`
+	Email           string `json:"email" validate:"required,email"`
+	CurrentPassword string `json:"currentPassword" validate:"max=30"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required,max=30"`
+	Password        string `json:"password" validate:"required,max=30"`
+}
+
+// RoleRequest names the role to act in or to add to the account.
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=student teacher"`
+}
+
+// ApiTokenRequest names a new personal access token, what it may do and for how many days it works.
+type ApiTokenRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Scope string `json:"scope" validate:"required,oneof=read write"`
+	Days  int    `json:"days" validate:"required,oneof=7 30 90 365"`
+}
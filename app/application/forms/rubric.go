@@ -0,0 +1,6 @@
+package forms
+
+type CreateRubricRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Criteria string `json:"criteria" validate:"required,max=2000"`
+}
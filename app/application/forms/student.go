@@ -1,5 +0,0 @@
-package forms
-
-type StudentUpdateRequest struct {
-	Teacher string `json:"teacher" validate:"required"`
-}
@@ -0,0 +1,15 @@
+package forms
+
+// TotpLoginRequest is the second step of signing in. The code is one from the authenticator app or a recovery code.
+type TotpLoginRequest struct {
+	Challenge string `json:"challenge" validate:"required"`
+	Code      string `json:"code" validate:"required,max=32"`
+}
+
+type TotpCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type TotpPolicyRequest struct {
+	Teachers string `json:"teachers" validate:"required,oneof=required optional"`
+}
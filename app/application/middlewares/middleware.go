@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/MikhailR1337/task-sync-x/app/initializers"
 	"github.com/gofiber/fiber/v2"
@@ -9,6 +10,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	jwtware "github.com/gofiber/jwt/v3"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,14 +23,50 @@ func AddCommonMiddleware(app *fiber.App) {
 	}))
 }
 
-func AddJwtMiddleware(app *fiber.App) {
+// AddJwtMiddleware accepts the token in the session cookie. checkSession runs for valid tokens to make sure
+// their session has not been revoked, errorHandler for missing or expired ones.
+func AddJwtMiddleware(app *fiber.App, checkSession fiber.Handler, errorHandler fiber.ErrorHandler) {
 	app.Use(jwtware.New(jwtware.Config{
-		TokenLookup: fmt.Sprintf("cookie:%s", initializers.Cfg.JwtCookieKey),
-		SigningKey:  []byte(initializers.Cfg.JwtSecretKey),
-		ContextKey:  initializers.Cfg.ContextKeyUser,
+		TokenLookup:    fmt.Sprintf("cookie:%s", initializers.Cfg.JwtCookieKey),
+		SigningKey:     []byte(initializers.Cfg.JwtSecretKey),
+		ContextKey:     initializers.Cfg.ContextKeyUser,
+		Filter:         signedIn,
+		SuccessHandler: checkSession,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			logrus.WithError(err)
-			return c.Redirect("/login")
+			return errorHandler(c, err)
 		},
 	}))
 }
+
+// AddApiJwtMiddleware accepts the token as a bearer token or, for browser clients, the cookie.
+// checkSession runs for valid tokens to make sure their session has not been revoked.
+func AddApiJwtMiddleware(router fiber.Router, checkSession fiber.Handler, errorHandler fiber.ErrorHandler) {
+	router.Use(jwtware.New(jwtware.Config{
+		TokenLookup:    fmt.Sprintf("header:%s,cookie:%s", fiber.HeaderAuthorization, initializers.Cfg.JwtCookieKey),
+		AuthScheme:     "Bearer",
+		SigningKey:     []byte(initializers.Cfg.JwtSecretKey),
+		ContextKey:     initializers.Cfg.ContextKeyUser,
+		Filter:         signedIn,
+		SuccessHandler: checkSession,
+		ErrorHandler:   errorHandler,
+	}))
+}
+
+// AddApiTokenMiddleware lets scripts in with a personal access token sent as the bearer token. checkToken runs
+// for bearer tokens with the prefix and signs the request in; the JWT middlewares then let it through as it is.
+func AddApiTokenMiddleware(router fiber.Router, prefix string, checkToken func(c *fiber.Ctx, token string) error) {
+	router.Use(func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || !strings.HasPrefix(token, prefix) {
+			return c.Next()
+		}
+		return checkToken(c, token)
+	})
+}
+
+// signedIn reports whether the request has been signed in already, by a personal access token.
+func signedIn(c *fiber.Ctx) bool {
+	_, ok := c.Locals(initializers.Cfg.ContextKeyUser).(*jwt.Token)
+	return ok
+}
@@ -0,0 +1,293 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Path is the well-known path the document is served at.
+const Path = "/openapi.json"
+
+// Security schemes operations can be authorized with.
+const (
+	CookieAuth   = "cookieAuth"
+	BearerAuth   = "bearerAuth"
+	ApiTokenAuth = "apiTokenAuth"
+)
+
+const (
+	mimeJSON      = "application/json"
+	mimeForm      = "application/x-www-form-urlencoded"
+	mimeMultipart = "multipart/form-data"
+)
+
+// Operation describes one registered route.
+type Operation struct {
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+	// Security lists the schemes the operation accepts, none for public operations.
+	Security []string
+	Query    []Parameter
+	// Body is the value the request body is parsed into, nil when the operation takes none.
+	Body interface{}
+	// Files lists the multipart fields files can be uploaded in.
+	Files     []string
+	Responses []Response
+}
+
+type Parameter struct {
+	Name        string
+	Description string
+}
+
+type Response struct {
+	Status      int
+	Description string
+	// Content is the media type of the body, empty when there is none.
+	Content string
+	// Body is the value a JSON body is encoded from.
+	Body interface{}
+}
+
+// Page is a rendered HTML page.
+func Page(status int, description string) Response {
+	return Response{Status: status, Description: description, Content: fiber.MIMETextHTMLCharsetUTF8}
+}
+
+// Redirect is a redirect to another page.
+func Redirect(description string) Response {
+	return Response{Status: fiber.StatusFound, Description: description}
+}
+
+// JSON is a JSON body encoded from body.
+func JSON(status int, description string, body interface{}) Response {
+	return Response{Status: status, Description: description, Content: mimeJSON, Body: body}
+}
+
+// Empty is a response without a body.
+func Empty(status int, description string) Response {
+	return Response{Status: status, Description: description}
+}
+
+// File is a downloaded file.
+func File(description string) Response {
+	return Response{Status: fiber.StatusOK, Description: description, Content: fiber.MIMEOctetStream}
+}
+
+type (
+	Document struct {
+		OpenAPI    string              `json:"openapi"`
+		Info       Info                `json:"info"`
+		Paths      map[string]PathItem `json:"paths"`
+		Components Components          `json:"components"`
+	}
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+	// PathItem holds the operations of a path by lower case method.
+	PathItem   map[string]*OperationObject
+	Components struct {
+		Schemas         map[string]*Schema         `json:"schemas"`
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+	}
+	OperationObject struct {
+		Summary     string                     `json:"summary,omitempty"`
+		Tags        []string                   `json:"tags,omitempty"`
+		OperationId string                     `json:"operationId"`
+		Parameters  []*ParameterObject         `json:"parameters,omitempty"`
+		RequestBody *RequestBody               `json:"requestBody,omitempty"`
+		Responses   map[string]*ResponseObject `json:"responses"`
+		Security    []map[string][]string      `json:"security"`
+	}
+	ParameterObject struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required"`
+		Schema      *Schema `json:"schema"`
+	}
+	RequestBody struct {
+		Required bool                  `json:"required"`
+		Content  map[string]*MediaType `json:"content"`
+	}
+	ResponseObject struct {
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Scheme       string `json:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		In           string `json:"in,omitempty"`
+		Name         string `json:"name,omitempty"`
+	}
+)
+
+// New builds the document describing the operations and the document itself.
+func New(title string, version string, operations ...[]Operation) *Document {
+	document := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				CookieAuth: {Type: "apiKey", In: "cookie", Name: initializers.Cfg.JwtCookieKey},
+				BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				// personal access tokens are bearer tokens too, made on the profile page
+				ApiTokenAuth: {Type: "http", Scheme: "bearer", BearerFormat: "personal access token"},
+			},
+		},
+	}
+	document.add(Operation{
+		Method:    fiber.MethodGet,
+		Path:      Path,
+		Summary:   "This OpenAPI document",
+		Tag:       "meta",
+		Responses: []Response{JSON(fiber.StatusOK, "The document", nil)},
+	})
+	for _, list := range operations {
+		for _, operation := range list {
+			document.add(operation)
+		}
+	}
+	return document
+}
+
+// Handler serves the document.
+func Handler(document *Document) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(document)
+	}
+}
+
+var paramPattern = regexp.MustCompile(`:(\w+)`)
+
+// PathOf converts a Fiber route path to an OpenAPI path template.
+func PathOf(route string) string {
+	return paramPattern.ReplaceAllString(route, "{$1}")
+}
+
+func (d *Document) add(operation Operation) {
+	path := PathOf(operation.Path)
+	object := &OperationObject{
+		Summary:     operation.Summary,
+		OperationId: operationId(operation.Method, path),
+		Responses:   map[string]*ResponseObject{},
+		Security:    []map[string][]string{},
+	}
+	if operation.Tag != "" {
+		object.Tags = []string{operation.Tag}
+	}
+	for _, name := range paramPattern.FindAllStringSubmatch(operation.Path, -1) {
+		object.Parameters = append(object.Parameters, &ParameterObject{
+			Name:     name[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "integer", Minimum: floatPtr(0)},
+		})
+	}
+	for _, parameter := range operation.Query {
+		object.Parameters = append(object.Parameters, &ParameterObject{
+			Name:        parameter.Name,
+			In:          "query",
+			Description: parameter.Description,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+	if operation.Body != nil || len(operation.Files) > 0 {
+		object.RequestBody = d.requestBody(operation)
+	}
+	for _, response := range operation.Responses {
+		object.Responses[strconv.Itoa(response.Status)] = d.response(response)
+	}
+	for _, scheme := range operation.Security {
+		object.Security = append(object.Security, map[string][]string{scheme: {}})
+	}
+	if d.Paths[path] == nil {
+		d.Paths[path] = PathItem{}
+	}
+	d.Paths[path][strings.ToLower(operation.Method)] = object
+}
+
+func (d *Document) requestBody(operation Operation) *RequestBody {
+	body := &RequestBody{Required: true, Content: map[string]*MediaType{}}
+	var schema *Schema
+	if operation.Body != nil {
+		schema = d.schemaOf(operation.Body)
+		body.Content[mimeJSON] = &MediaType{Schema: schema}
+		if !isArray(operation.Body) {
+			body.Content[mimeForm] = &MediaType{Schema: schema}
+		}
+	}
+	if len(operation.Files) > 0 {
+		files := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, name := range operation.Files {
+			files.Properties[name] = &Schema{Type: "array", Items: &Schema{Type: "string", Format: "binary"}}
+		}
+		multipart := files
+		if schema != nil && !isArray(operation.Body) {
+			multipart = &Schema{AllOf: []*Schema{schema, files}}
+		}
+		body.Content[mimeMultipart] = &MediaType{Schema: multipart}
+	}
+	return body
+}
+
+func (d *Document) response(response Response) *ResponseObject {
+	object := &ResponseObject{Description: response.Description}
+	if response.Content == "" {
+		return object
+	}
+	schema := &Schema{Type: "string"}
+	switch {
+	case response.Body != nil:
+		schema = d.schemaOf(response.Body)
+	case response.Content == mimeJSON:
+		schema = &Schema{Type: "object"}
+	case response.Content == fiber.MIMEOctetStream:
+		schema = &Schema{Type: "string", Format: "binary"}
+	}
+	object.Content = map[string]*MediaType{response.Content: {Schema: schema}}
+	return object
+}
+
+// operationId names the operation after its method and path, e.g. patchHomeworksId.
+func operationId(method string, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '.' || r == '-' || r == '_'
+	}) {
+		id.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return id.String()
+}
+
+// Missing returns the routes that are not described by the document.
+func (d *Document) Missing(routes []fiber.Route) []string {
+	missing := []string{}
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		item := d.Paths[PathOf(route.Path)]
+		if item == nil || item[strings.ToLower(route.Method)] == nil {
+			missing = append(missing, fmt.Sprintf("%s %s", route.Method, route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
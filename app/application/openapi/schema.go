@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is the subset of the OpenAPI 3.0 schema object the documented types need.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// patterns of the validator rules that only constrain the format of strings.
+var patterns = map[string]string{
+	"numeric":  `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"hexcolor": `^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`,
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the schema of value's type. Named structs are added to the
+// components and referenced, so every form is described once.
+func (d *Document) schemaOf(value interface{}) *Schema {
+	return d.schemaOfType(reflect.TypeOf(value))
+}
+
+func (d *Document) schemaOfType(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := d.schemaOfType(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: floatPtr(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		name := componentName(t)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// reserve the name first so recursive types terminate
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	d.addFields(schema, t)
+	return schema
+}
+
+// addFields adds the JSON fields of t to the schema, flattening embedded structs the way encoding/json does.
+func (d *Document) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			d.addFields(schema, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := d.schemaOfType(field.Type)
+		if applyRules(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules describes the validator rules in the schema and reports whether the field is required.
+func applyRules(schema *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			if schema.Items != nil {
+				applyRules(schema.Items, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "required":
+			required = true
+		case "required_if":
+			field, value, _ := strings.Cut(param, " ")
+			schema.Description = "Required when " + lowerFirst(field) + " is " + value + "."
+		case "required_without":
+			schema.Description = "Required without " + lowerFirst(param) + "."
+		case "email":
+			schema.Format = "email"
+		case "datetime":
+			schema.Description = "Local date and time in the Go layout " + param + "."
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "max", "lte", "lt":
+			limit(schema, param, false, name == "lt")
+		case "min", "gte", "gt":
+			limit(schema, param, true, name == "gt")
+		default:
+			if pattern, ok := patterns[name]; ok {
+				schema.Pattern = pattern
+			}
+		}
+	}
+	return required
+}
+
+// limit applies a length limit to strings and arrays and a value limit to numbers.
+func limit(schema *Schema, param string, lower bool, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = intPtr(int(value))
+		} else {
+			schema.MaxLength = intPtr(int(value))
+		}
+	case "array":
+		if lower {
+			schema.MinItems = intPtr(int(value))
+		} else {
+			schema.MaxItems = intPtr(int(value))
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = floatPtr(value)
+			schema.ExclusiveMinimum = exclusive
+		} else {
+			schema.Maximum = floatPtr(value)
+			schema.ExclusiveMaximum = exclusive
+		}
+	}
+}
+
+// componentName names the schema after the Go type, so unexported response types read as exported ones.
+func componentName(t reflect.Type) string {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
+
+func isArray(value interface{}) bool {
+	kind := reflect.TypeOf(value).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
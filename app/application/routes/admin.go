@@ -0,0 +1,210 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/admin"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var AdminHandler = &adminHandler{}
+
+type adminHandler struct{}
+
+// Get lists the teachers and students, only the ones matching the q parameter if it is given.
+func (h *adminHandler) Get(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	query := c.Query("q")
+	users, err := admin.Search(actor, query)
+	if err != nil {
+		return renderFailure(c, "admin", err)
+	}
+	return c.Render("admin", fiber.Map{
+		"query":        query,
+		"teachers":     users.Teachers,
+		"students":     users.Students,
+		"totpRequired": account.TeacherTotpRequired(),
+	})
+}
+
+// UpdateTotp makes two-factor authentication mandatory for teachers or leaves it to them.
+func (h *adminHandler) UpdateTotp(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.TotpPolicyRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("admin", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if err := admin.SetTeacherTotp(actor, req); err != nil {
+		return renderFailure(c, "admin", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// UpdateTeacher deactivates or reactivates the teacher's account.
+func (h *adminHandler) UpdateTeacher(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.AccountStatusRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("admin", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if _, err := admin.SetTeacherStatus(actor, id, req); err != nil {
+		return renderFailure(c, "admin", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// UpdateStudent deactivates or reactivates the student's account.
+func (h *adminHandler) UpdateStudent(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.AccountStatusRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("admin", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if _, err := admin.SetStudentStatus(actor, id, req); err != nil {
+		return renderFailure(c, "admin", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// GetStudent shows the student's teachers and homework.
+func (h *adminHandler) GetStudent(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	detail, err := admin.Student(actor, id)
+	if err != nil {
+		return renderFailure(c, "adminStudent", err)
+	}
+	return c.Render("adminStudent", fiber.Map{
+		"student":     detail.Student,
+		"enrollments": detail.Enrollments,
+		"homeworks":   detail.Homeworks,
+		"teachers":    detail.Teachers,
+	})
+}
+
+// UpdateEnrollment moves the enrolled student to another teacher.
+func (h *adminHandler) UpdateEnrollment(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.ReassignRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("adminStudent", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if _, err := admin.ReassignStudent(actor, id, req); err != nil {
+		return renderFailure(c, "adminStudent", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// GetHomework shows any homework without the forms to change it.
+func (h *adminHandler) GetHomework(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	detail, err := admin.Homework(actor, id)
+	if err != nil {
+		return renderFailure(c, "homework", err)
+	}
+	return c.Render("homework", homeworkPage(actor, detail))
+}
+
+func (h *adminHandler) GetFile(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	fileId, err := paramId(c, "fileId")
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	file, reader, err := admin.File(actor, homeworkId, fileId)
+	if err != nil {
+		logrus.WithError(err)
+		return c.SendStatus(utilities.StatusOf(err))
+	}
+	c.Attachment(file.Name)
+	return c.SendStream(reader)
+}
+
+// GetAudit shows the latest actions of the admins.
+func (h *adminHandler) GetAudit(c *fiber.Ctx) error {
+	actor, err := currentAdmin(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	entries, err := admin.Audit(actor)
+	if err != nil {
+		return renderFailure(c, "audit", err)
+	}
+	return c.Render("audit", fiber.Map{
+		"entries": entries,
+	})
+}
+
+// currentAdmin returns the signed in admin, forbidding everyone else.
+func currentAdmin(c *fiber.Ctx) (*account.Actor, error) {
+	actor, err := signedIn(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var ApiTokenHandler = &apiTokensHandler{}
+
+type apiTokensHandler struct{}
+
+// Create makes a personal access token acting in the current role and shows it this once.
+func (h *apiTokensHandler) Create(c *fiber.Ctx) error {
+	actor, err := managing(c, currentActor)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.ApiTokenRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("apiToken", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	created, err := account.CreateApiToken(actor, req)
+	if err != nil {
+		return renderFailure(c, "apiToken", err)
+	}
+	return c.Status(fiber.StatusCreated).Render("apiToken", fiber.Map{
+		"token":  created.Token,
+		"secret": created.Secret,
+	})
+}
+
+// Delete revokes one of the personal access tokens.
+func (h *apiTokensHandler) Delete(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	if err := account.RevokeApiToken(actor, id); err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// CheckApiToken signs the request in with the personal access token, which has to be allowed to do what the request does.
+// Scripts do not sign in with a form, so a token that does not work gets the error page rather than the sign in page.
+func CheckApiToken(c *fiber.Ctx, token string) error {
+	claims, err := account.CheckApiToken(token, utilities.Writes(c))
+	if err != nil {
+		logrus.WithError(err)
+		return c.Status(utilities.StatusOf(err)).Render("error", fiber.Map{
+			"error": err,
+		})
+	}
+	utilities.SetJwtPayload(c, claims)
+	return c.Next()
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/services/homeworks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var CommentHandler = &commentsHandler{}
+
+type commentsHandler struct{}
+
+func (h *commentsHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.CreateCommentRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("homework", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if _, err := homeworks.Comment(actor, homeworkId, req); err != nil {
+		return renderFailure(c, "homework", err)
+	}
+	return c.Redirect(fmt.Sprintf("/homeworks/%d", homeworkId))
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var GradebookHandler = &gradebookHandler{}
+
+type (
+	gradebookHandler struct{}
+	gradebookRow     struct {
+		StudentId   uint
+		StudentName string
+		Cells       []*repository.GradebookCell
+	}
+)
+
+func (h *gradebookHandler) Get(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	var studentId uint
+	if c.Query("student") != "" {
+		student, err := findStudent(c.Query("student"))
+		if err != nil {
+			return renderError(c, err)
+		}
+		if err := policy.Student(actor, policy.View, student); err != nil {
+			return renderError(c, err)
+		}
+		studentId = student.Id
+	}
+	assignments, err := repository.Gradebook.GetAssignments(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("gradebook", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	cells, err := repository.Gradebook.GetCells(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("gradebook", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	progress, err := repository.Gradebook.GetProgress(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("gradebook", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	trend, err := repository.Gradebook.GetTrend(actor.Teacher.Id, studentId)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("gradebook", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Render("gradebook", fiber.Map{
+		"assignments": assignments,
+		"rows":        newGradebookRows(assignments, *cells),
+		"progress":    *progress,
+		"trend":       *trend,
+		"student":     studentId,
+	})
+}
+
+// newGradebookRows lays the cells out as a students by assignments matrix.
+func newGradebookRows(assignments []repository.GradebookAssignment, cells []repository.GradebookCell) []gradebookRow {
+	columns := make(map[repository.GradebookAssignment]int, len(assignments))
+	for i, assignment := range assignments {
+		columns[assignment] = i
+	}
+	rows := []gradebookRow{}
+	studentRows := map[uint]int{}
+	for i := range cells {
+		cell := &cells[i]
+		row, ok := studentRows[cell.StudentId]
+		if !ok {
+			row = len(rows)
+			studentRows[cell.StudentId] = row
+			rows = append(rows, gradebookRow{
+				StudentId:   cell.StudentId,
+				StudentName: cell.StudentName,
+				Cells:       make([]*repository.GradebookCell, len(assignments)),
+			})
+		}
+		if column, ok := columns[repository.GradebookAssignment{Name: cell.Name, Number: cell.Number}]; ok {
+			rows[row].Cells[column] = cell
+		}
+	}
+	return rows
+}
@@ -0,0 +1,156 @@
+package routes
+
+import (
+	"strconv"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var GroupHandler = &groupsHandler{}
+
+type groupsHandler struct{}
+
+func (h *groupsHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.CreateGroupRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	err = initializers.Validator.Struct(req)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errValidation,
+		})
+	}
+	err = repository.Group.Create(&models.Group{
+		Name:      req.Name,
+		TeacherId: actor.Teacher.Id,
+	})
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Redirect("/profile")
+}
+
+func (h *groupsHandler) Delete(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	group, err := h.group(actor, c.Params("id"), policy.Delete)
+	if err != nil {
+		return renderError(c, err)
+	}
+	err = repository.Group.Delete(group)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *groupsHandler) AddStudent(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	group, err := h.group(actor, c.Params("id"), policy.Update)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.GroupStudentRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	err = initializers.Validator.Struct(req)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errValidation,
+		})
+	}
+	student, err := h.student(actor, req.Student)
+	if err != nil {
+		return renderError(c, err)
+	}
+	err = repository.Group.AddStudent(group, student)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Redirect("/profile")
+}
+
+func (h *groupsHandler) RemoveStudent(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	group, err := h.group(actor, c.Params("id"), policy.Update)
+	if err != nil {
+		return renderError(c, err)
+	}
+	student, err := h.student(actor, c.Params("studentId"))
+	if err != nil {
+		return renderError(c, err)
+	}
+	err = repository.Group.RemoveStudent(group, student)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *groupsHandler) group(actor *account.Actor, param string, action policy.Action) (*models.Group, error) {
+	groupId, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		return nil, errNotFound
+	}
+	group, err := repository.Group.GetById(uint(groupId))
+	if err != nil {
+		return nil, errNotFound
+	}
+	if err := policy.Group(actor, action, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// student returns the teacher's student to put into or take out of a group.
+func (h *groupsHandler) student(actor *account.Actor, param string) (*models.Student, error) {
+	student, err := findStudent(param)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Student(actor, policy.Update, student); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
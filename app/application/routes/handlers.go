@@ -2,6 +2,7 @@ package routes
 
 import (
 	"errors"
+	"mime/multipart"
 	"strconv"
 	"time"
 
@@ -10,9 +11,14 @@ import (
 	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
 	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
 	"github.com/MikhailR1337/task-sync-x/app/initializers"
-	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/deadline"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/homeworks"
+	"github.com/MikhailR1337/task-sync-x/app/services/joins"
+	"github.com/MikhailR1337/task-sync-x/app/services/lifecycle"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,18 +29,19 @@ var (
 	ProfileHandler      = &profileHandler{}
 	HomeworkHandler     = &homeworksHandler{}
 	Roles               = roles{
-		Teacher: "teacher",
-		Student: "student",
+		Teacher: account.Teacher,
+		Student: account.Student,
 	}
 )
 
 var (
-	errNotFound       = errors.New("404 Oops, page not found")
-	errSomethingWrong = errors.New("something wrong. try again")
-	errConflict       = errors.New("oops... we already have this email")
-	errBadCredentials = errors.New("email or password is incorrect")
-	errValidation     = errors.New("something wrong with your data. change something and try again")
-	errPoints         = errors.New("points should be a number")
+	errNotFound       = failure.ErrNotFound
+	errSomethingWrong = failure.ErrSomethingWrong
+	errValidation     = failure.ErrValidation
+	errForbidden      = failure.ErrForbidden
+	errType           = homeworks.ErrType
+	errTypeConflict   = errors.New("you already have a homework type with this name")
+	errCriteria       = errors.New("write every criterion on its own line as name: max points")
 )
 
 type (
@@ -47,6 +54,11 @@ type (
 		Teacher string
 		Student string
 	}
+	homeworkItem struct {
+		models.Homework
+		Deadline deadline.Status
+		Color    string
+	}
 )
 
 func (h *mainPageHandler) Get(c *fiber.Ctx) error {
@@ -65,66 +77,19 @@ func (h *registrationHandler) Registrate(c *fiber.Ctx) error {
 			"error": errSomethingWrong,
 		})
 	}
-	err := initializers.Validator.Struct(req)
-	if err != nil {
-		logrus.WithError(err)
-		return c.Render("registration", fiber.Map{
-			"error": errValidation,
-		})
-	}
-	password, err := utilities.HashPassword(req.Password)
-	if err != nil {
-		logrus.WithError(err)
-		return c.Render("registration", fiber.Map{
-			"error": errSomethingWrong,
-		})
-	}
-	if req.Role == Roles.Teacher {
-		_, err := repository.Teacher.GetByEmail(req.Email)
-		if err == nil {
-			logrus.WithError(err)
-			return c.Render("registration", fiber.Map{
-				"error": errConflict,
-			})
-		}
-		newTeacher := &models.Teacher{
-			Name:     req.Name,
-			Email:    req.Email,
-			Password: password,
-		}
-		err = repository.Teacher.Create(newTeacher)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("registration", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-	} else if req.Role == Roles.Student {
-		_, err := repository.Student.GetByEmail(req.Email)
-		if err == nil {
-			logrus.WithError(err)
-			return c.Render("registration", fiber.Map{
-				"error": errConflict,
-			})
-		}
-		newStudent := &models.Student{
-			Name:     req.Name,
-			Email:    req.Email,
-			Password: password,
-		}
-		err = repository.Student.Create(newStudent)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("registration", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
+	if _, err := account.Register(req, func(token string) string {
+		return verifyLinkOf(c, token)
+	}); err != nil {
+		return renderFailure(c, "registration", err)
 	}
 	return c.Status(fiber.StatusCreated).Redirect("/login")
 }
 
 func (h *loginHandler) Get(c *fiber.Ctx) error {
-	return c.Render("login", fiber.Map{})
+	return c.Render("login", fiber.Map{
+		"oidc":     account.OidcEnabled(),
+		"oidcName": initializers.Cfg.OidcName,
+	})
 }
 
 func (h *loginHandler) Login(c *fiber.Ctx) error {
@@ -134,270 +99,358 @@ func (h *loginHandler) Login(c *fiber.Ctx) error {
 			"error": errSomethingWrong,
 		})
 	}
-
-	err := initializers.Validator.Struct(req)
+	actor, err := account.Login(req, c.IP())
 	if err != nil {
+		logrus.WithError(err)
+		utilities.SetRetryAfter(c, err)
+		return c.Status(utilities.StatusOf(err)).Render("login", fiber.Map{
+			"error": failureMessage(err),
+		})
+	}
+	if actor.TotpEnabled() {
+		return askForCode(c, actor)
+	}
+	if err := startSession(c, actor); err != nil {
 		logrus.WithError(err)
 		return c.Render("login", fiber.Map{
-			"error": errValidation,
+			"error": errSomethingWrong,
 		})
 	}
-	if req.Role == Roles.Teacher {
-		teacher, err := repository.Teacher.GetByEmail(req.Email)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("login", fiber.Map{
-				"error": errBadCredentials,
-			})
-		}
-		if !utilities.CheckPasswordHash(req.Password, teacher.Password) {
-			logrus.WithError(err)
-			return c.Render("login", fiber.Map{
-				"error": errBadCredentials,
-			})
-		}
-	} else if req.Role == Roles.Student {
-		student, err := repository.Student.GetByEmail(req.Email)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("login", fiber.Map{
-				"error": errBadCredentials,
-			})
-		}
-		if !utilities.CheckPasswordHash(req.Password, student.Password) {
-			logrus.WithError(err)
-			return c.Render("login", fiber.Map{
-				"error": errBadCredentials,
-			})
-		}
+	if actor.IsAdmin() {
+		return c.Redirect("/admin")
 	}
+	return c.Redirect("/profile")
+}
 
-	payload := jwt.MapClaims{
-		"sub":   req.Email,
-		"roles": req.Role,
-		"exp":   time.Now().Add(time.Hour * 72).Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	t, err := token.SignedString([]byte(initializers.Cfg.JwtSecretKey))
+// Oidc sends the user to sign in at the identity provider. The cookie remembers the attempt until they come back.
+func (h *loginHandler) Oidc(c *fiber.Ctx) error {
+	start, err := account.StartOidc(oidcRedirectOf(c))
+	if err != nil {
+		return renderFailure(c, "login", err)
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     initializers.Cfg.OidcCookieKey,
+		Value:    start.Attempt,
+		Expires:  start.ExpiresAt,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(start.Url)
+}
+
+// OidcCallback signs in the user the identity provider sent back, asking for the authenticator code of the ones who set it up.
+func (h *loginHandler) OidcCallback(c *fiber.Ctx) error {
+	attempt := c.Cookies(initializers.Cfg.OidcCookieKey)
+	c.ClearCookie(initializers.Cfg.OidcCookieKey)
+	// the provider sends the user back with an error when they refuse or it cannot sign them in
+	if c.Query("error") != "" {
+		return renderFailure(c, "login", account.ErrOidcFailed)
+	}
+	actor, err := account.FinishOidc(attempt, c.Query("state"), c.Query("code"), oidcRedirectOf(c))
 	if err != nil {
+		logrus.WithError(err)
+		return renderFailure(c, "login", err)
+	}
+	if actor.TotpEnabled() {
+		return askForCode(c, actor)
+	}
+	if err := startSession(c, actor); err != nil {
 		logrus.WithError(err)
 		return c.Render("login", fiber.Map{
 			"error": errSomethingWrong,
 		})
 	}
-	cookie := new(fiber.Cookie)
-	cookie.Name = initializers.Cfg.JwtCookieKey
-	cookie.Value = t
-	cookie.Expires = time.Now().Add(72 * time.Hour)
-	cookie.HTTPOnly = true
-
-	c.Cookie(cookie)
 	return c.Redirect("/profile")
 }
 
+// oidcRedirectOf is where the identity provider sends the user back to.
+func oidcRedirectOf(c *fiber.Ctx) string {
+	return c.BaseURL() + "/login/oidc/callback"
+}
+
 func (h *loginHandler) SignOut(c *fiber.Ctx) error {
-	c.ClearCookie(initializers.Cfg.JwtCookieKey)
+	if err := account.SignOut(c.Cookies(initializers.Cfg.RefreshCookieKey)); err != nil {
+		logrus.WithError(err)
+	}
+	endSession(c)
 	return c.SendStatus(fiber.StatusOK)
 }
 
 func (h *profileHandler) Get(c *fiber.Ctx) error {
-	jwtPayload, err := utilities.GetJwtPayload(c)
+	actor, err := currentAccount(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
+		return renderError(c, err)
 	}
-	role := jwtPayload["roles"].(string)
-	if role == Roles.Teacher {
-		teacher, err := repository.Teacher.GetByEmail(jwtPayload["sub"].(string))
+	if actor.IsTeacher() {
+		students, err := account.Students(actor)
 		if err != nil {
-			logrus.WithError(err)
-			return c.Redirect("/login")
+			return renderFailure(c, "profileTeacher", err)
 		}
-		students, err := repository.Student.GetByTeacherId(teacher.Id)
+		enrollments, err := joins.EnrollmentsOf(actor)
+		if err != nil {
+			return renderFailure(c, "profileTeacher", err)
+		}
+		groups, err := repository.Group.GetByTeacherId(actor.Teacher.Id)
 		if err != nil {
 			logrus.WithError(err)
 			return c.Render("profileTeacher", fiber.Map{
 				"error": errSomethingWrong,
 			})
 		}
+		requests, err := joins.PendingOf(actor)
+		if err != nil {
+			return renderFailure(c, "profileTeacher", err)
+		}
+		invite, err := joins.InviteOf(actor)
+		if err != nil {
+			return renderFailure(c, "profileTeacher", err)
+		}
+		apiTokens, err := account.ApiTokens(actor)
+		if err != nil {
+			return renderFailure(c, "profileTeacher", err)
+		}
+		var inviteLink string
+		if invite != nil {
+			inviteLink = inviteLinkOf(c, invite)
+		}
 		return c.Render("profileTeacher", fiber.Map{
-			"email":    teacher.Email,
-			"name":     teacher.Name,
-			"role":     Roles.Teacher,
-			"students": *students,
+			"email":             actor.Email(),
+			"unverified":        !actor.Verified(),
+			"name":              actor.Name(),
+			"role":              Roles.Teacher,
+			"otherRole":         Roles.Student,
+			"hasOtherRole":      actor.Has(Roles.Student),
+			"students":          students,
+			"enrollments":       enrollments,
+			"groups":            *groups,
+			"joinRequests":      requests,
+			"inviteLink":        inviteLink,
+			"totpEnabled":       actor.TotpEnabled(),
+			"totpMissing":       account.CheckTotp(actor) != nil,
+			"recoveryCodesLeft": account.RecoveryCodesLeft(actor),
+			"apiTokens":         apiTokens,
 		})
 	}
-	student, err := repository.Student.GetByEmail(jwtPayload["sub"].(string))
+	enrollments, err := joins.EnrollmentsOf(actor)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
+		return renderFailure(c, "profileStudent", err)
 	}
-	if student.TeacherId != 0 {
-		teacher, err := repository.Teacher.GetById(student.TeacherId)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("/profileStudent", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-		return c.Render("profileStudent", fiber.Map{
-			"email":       student.Email,
-			"name":        student.Name,
-			"role":        Roles.Student,
-			"teacherName": teacher.Name,
-		})
+	requests, err := joins.PendingOf(actor)
+	if err != nil {
+		return renderFailure(c, "profileStudent", err)
 	}
-	teachers, err := repository.Teacher.GetList()
+	apiTokens, err := account.ApiTokens(actor)
+	if err != nil {
+		return renderFailure(c, "profileStudent", err)
+	}
+	teachers, err := account.Teachers()
 	if err != nil {
 		return c.Render("profileStudent", fiber.Map{
-			"email": student.Email,
-			"name":  student.Name,
-			"role":  Roles.Student,
+			"email":        actor.Email(),
+			"unverified":   !actor.Verified(),
+			"name":         actor.Name(),
+			"role":         Roles.Student,
+			"otherRole":    Roles.Teacher,
+			"hasOtherRole": actor.Has(Roles.Teacher),
+			"enrollments":  enrollments,
+			"joinRequests": requests,
+			"apiTokens":    apiTokens,
 		})
 	}
 	return c.Render("profileStudent", fiber.Map{
-		"email":    student.Email,
-		"name":     student.Name,
-		"role":     Roles.Student,
-		"teachers": *teachers,
+		"email":        actor.Email(),
+		"unverified":   !actor.Verified(),
+		"name":         actor.Name(),
+		"role":         Roles.Student,
+		"otherRole":    Roles.Teacher,
+		"hasOtherRole": actor.Has(Roles.Teacher),
+		"teachers":     teachers,
+		"enrollments":  enrollments,
+		"joinRequests": requests,
+		"apiTokens":    apiTokens,
 	})
 }
 
+// Update changes the name and email; a new email has to be confirmed again.
 func (h *profileHandler) Update(c *fiber.Ctx) error {
-	jwtPayload, err := utilities.GetJwtPayload(c)
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.UpdateProfileRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render(profilePageOf(actor), fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if err := account.UpdateProfile(actor, req, func(token string) string {
+		return verifyLinkOf(c, token)
+	}); err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// UpdatePassword changes the password and signs out every other session.
+func (h *profileHandler) UpdatePassword(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
 	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.ChangePasswordRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render(profilePageOf(actor), fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if err := account.ChangePassword(actor, req); err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
+	}
+	if err := startSession(c, actor); err != nil {
 		logrus.WithError(err)
 		return c.Redirect("/login")
 	}
-	role := jwtPayload["roles"].(string)
-	req := forms.StudentUpdateRequest{}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// SwitchRole makes this device act in the other role of the user.
+func (h *profileHandler) SwitchRole(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.RoleRequest{}
 	if err := c.BodyParser(&req); err != nil {
 		logrus.WithError(err)
-		if role == Roles.Student {
-			return c.Render("profileStudent", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		} else {
-			return c.Render("profileTeacher", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
+		return c.Render(profilePageOf(actor), fiber.Map{
+			"error": errSomethingWrong,
+		})
 	}
-	if role == Roles.Student {
-		student, err := repository.Student.GetByEmail(jwtPayload["sub"].(string))
-		if err != nil {
-			logrus.WithError(err)
-			return c.Redirect("/login")
-		}
-		teacherId, err := strconv.ParseUint(req.Teacher, 10, 32)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("profileStudent", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-		student.TeacherId = uint(teacherId)
-		repository.Student.Update(student)
-		return c.SendStatus(fiber.StatusOK)
+	tokens, err := account.SwitchRole(actor, req, deviceOf(c))
+	if err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
+	}
+	setSessionCookies(c, tokens)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// AddRole lets the user teach as well as study, or the other way round, and switches this device to the new role.
+func (h *profileHandler) AddRole(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.RoleRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render(profilePageOf(actor), fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if err := account.AddRole(actor, req); err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
+	}
+	tokens, err := account.SwitchRole(actor, req, deviceOf(c))
+	if err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
 	}
-	return c.Status(fiber.StatusNotFound).Redirect("/")
+	setSessionCookies(c, tokens)
+	return c.Redirect("/profile")
 }
 
 func (h *profileHandler) Delete(c *fiber.Ctx) error {
-	jwtPayload, err := utilities.GetJwtPayload(c)
+	actor, err := managing(c, currentAccount)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
+		return renderError(c, err)
 	}
-	role := jwtPayload["roles"].(string)
-	if role == Roles.Teacher {
-		teacher, err := repository.Teacher.GetByEmail(jwtPayload["sub"].(string))
-		if err != nil {
-			logrus.WithError(err)
-			return c.Redirect("/login")
-		}
-		err = repository.Teacher.Delete(teacher)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("profileTeacher", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-		err = repository.Homework.DeleteByTeacherId(teacher.Id)
-		if err != nil {
-			logrus.WithError(err)
-		}
-	} else {
-		student, err := repository.Student.GetByEmail(jwtPayload["sub"].(string))
-		if err != nil {
-			logrus.WithError(err)
-			return c.Redirect("/login")
-		}
-		err = repository.Student.Delete(student)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("profileStudent", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-		err = repository.Homework.DeleteByStudentId(student.Id)
-		if err != nil {
-			logrus.WithError(err)
-		}
+	if err := account.Delete(actor); err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
 	}
-	c.ClearCookie(initializers.Cfg.JwtCookieKey)
+	endSession(c)
 	return c.SendStatus(fiber.StatusOK)
 }
 
 func (h *homeworksHandler) GetList(c *fiber.Ctx) error {
-	jwtPayload, err := utilities.GetJwtPayload(c)
+	actor, err := currentActor(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
+		return renderError(c, err)
 	}
-	role := jwtPayload["roles"].(string)
-	if role == Roles.Teacher {
-		teacher, err := repository.Teacher.GetByEmail(jwtPayload["sub"].(string))
-		if err != nil {
-			logrus.WithError(err)
-			return c.Redirect("/login")
-		}
-		students, err := repository.Student.GetByTeacherId(teacher.Id)
+	filter := repository.HomeworkFilter{
+		Order: repository.OrderByStatus,
+		Type:  c.Query("type"),
+	}
+	if c.Query("sort") == "urgency" {
+		filter.Order = repository.OrderByUrgency
+	}
+	list, err := homeworks.List(actor, filter)
+	if err != nil {
+		return renderFailure(c, "homeworks", err)
+	}
+	if !actor.IsTeacher() {
+		types, err := repository.HomeworkType.GetAvailableToStudent(actor.Student.Id)
 		if err != nil {
 			logrus.WithError(err)
 			return c.Render("homeworks", fiber.Map{
 				"error": errSomethingWrong,
 			})
 		}
-		homeworks, err := repository.Homework.GetByTeacherId(teacher.Id)
-		if err != nil {
-			return c.Render("homeworks", fiber.Map{})
-		}
 		return c.Render("homeworks", fiber.Map{
-			"homeworks": *homeworks,
-			"students":  *students,
-			"isTeacher": true,
+			"homeworks": newHomeworkItems(list, *types),
+			"types":     distinctTypes(*types),
+			"type":      filter.Type,
 		})
 	}
-	student, err := repository.Student.GetByEmail(jwtPayload["sub"].(string))
+	teacher := actor.Teacher
+	students, err := account.Students(actor)
+	if err != nil {
+		return renderFailure(c, "homeworks", err)
+	}
+	groups, err := repository.Group.GetByTeacherId(teacher.Id)
 	if err != nil {
 		logrus.WithError(err)
-		return c.Redirect("/login")
+		return c.Render("homeworks", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	var template *models.HomeworkTemplate
+	if c.Query("template") != "" {
+		template, err = TemplateHandler.template(actor, c.Query("template"), policy.View)
+		if err != nil {
+			return renderError(c, err)
+		}
+	}
+	types, err := repository.HomeworkType.GetAvailable(teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("homeworks", fiber.Map{
+			"error": errSomethingWrong,
+		})
 	}
-	homeworks, err := repository.Homework.GetByStudentId(student.Id)
+	rubrics, err := repository.Rubric.GetByTeacherId(teacher.Id)
 	if err != nil {
-		return c.Render("homeworks", fiber.Map{})
+		logrus.WithError(err)
+		return c.Render("homeworks", fiber.Map{
+			"error": errSomethingWrong,
+		})
 	}
 	return c.Render("homeworks", fiber.Map{
-		"homeworks": *homeworks,
+		"homeworks": newHomeworkItems(list, *types),
+		"students":  students,
+		"groups":    *groups,
+		"rubrics":   *rubrics,
+		"template":  template,
+		"types":     *types,
+		"type":      filter.Type,
+		"isTeacher": true,
 	})
 }
 
 func (h *homeworksHandler) Create(c *fiber.Ctx) error {
-	jwtPayload, err := utilities.GetJwtPayload(c)
+	actor, err := currentActor(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
+		return renderError(c, err)
 	}
 	req := forms.CreateHomeworkRequest{}
 	if err := c.BodyParser(&req); err != nil {
@@ -406,104 +459,201 @@ func (h *homeworksHandler) Create(c *fiber.Ctx) error {
 			"error": errSomethingWrong,
 		})
 	}
-	err = initializers.Validator.Struct(req)
+	if _, err := homeworks.Create(actor, req); err != nil {
+		return renderFailure(c, "homeworks", err)
+	}
+	return c.Redirect("/homeworks")
+}
+
+func (h *homeworksHandler) Get(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homeworks", fiber.Map{
-			"error": errValidation,
-		})
+		return renderError(c, err)
 	}
-	teacher, err := repository.Teacher.GetByEmail(jwtPayload["sub"].(string))
+	homeworkId, err := paramId(c, "id")
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
+		return renderError(c, err)
 	}
-	currentPoints, err := strconv.ParseUint(req.CurrentPoints, 10, 32)
+	detail, err := homeworks.Get(actor, homeworkId)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homeworks", fiber.Map{
-			"error": errPoints,
-		})
+		return renderFailure(c, "homework", err)
 	}
-	maxPoints, err := strconv.ParseUint(req.MaxPoints, 10, 32)
+	return c.Render("homework", homeworkPage(actor, detail))
+}
+
+func (h *homeworksHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homeworks", fiber.Map{
-			"error": errPoints,
-		})
+		return renderError(c, err)
 	}
-	studentId, err := strconv.ParseUint(req.Student, 10, 32)
+	homeworkId, err := paramId(c, "id")
 	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.UpdateHomeworkRequest{}
+	if err := c.BodyParser(&req); err != nil {
 		logrus.WithError(err)
-		return c.Render("homeworks", fiber.Map{
+		return c.Render("homework", fiber.Map{
 			"error": errSomethingWrong,
 		})
 	}
+	var files []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		files = req.ReadMultipart(form)
+	}
+	if _, err := homeworks.Update(actor, homeworkId, req, files); err != nil {
+		return renderFailure(c, "homework", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
 
-	newHomework := models.Homework{
-		Name:          req.Name,
-		Description:   req.Description,
-		CurrentPoints: uint8(currentPoints),
-		MaxPoints:     uint8(maxPoints),
-		Type:          req.Type,
-		Status:        req.Status,
-		TeacherId:     teacher.Id,
-		StudentId:     uint(studentId),
+func (h *homeworksHandler) Delete(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	if err := homeworks.Delete(actor, homeworkId); err != nil {
+		return renderFailure(c, "homework", err)
 	}
+	return c.SendStatus(fiber.StatusOK)
+}
 
-	err = repository.Homework.Create(&newHomework)
+// currentActor returns the signed in teacher or student who has confirmed their email and set up two-factor authentication
+// where admins require it. Admins work in the console only.
+func currentActor(c *fiber.Ctx) (*account.Actor, error) {
+	actor, err := signedIn(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homeworks", fiber.Map{
-			"error": errSomethingWrong,
-		})
+		return nil, err
 	}
-	student, err := repository.Student.GetById(uint(studentId))
+	if actor.IsAdmin() {
+		return nil, errForbidden
+	}
+	if !actor.Verified() {
+		return nil, account.ErrUnverified
+	}
+	if err := account.CheckTotp(actor); err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+// currentAccount returns the signed in teacher or student, letting in the ones who have not confirmed their email yet.
+func currentAccount(c *fiber.Ctx) (*account.Actor, error) {
+	actor, err := signedIn(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homeworks", fiber.Map{
-			"error": errSomethingWrong,
-		})
+		return nil, err
 	}
-	mailer.NewHomework(student.Email, student.Name, newHomework.Name)
-	return c.Redirect("/homeworks")
+	if actor.IsAdmin() {
+		return nil, errForbidden
+	}
+	return actor, nil
 }
 
-func (h *homeworksHandler) Get(c *fiber.Ctx) error {
+// managing returns the actor find signs in unless the request came with a personal access token: only sessions manage the account.
+func managing(c *fiber.Ctx, find func(*fiber.Ctx) (*account.Actor, error)) (*account.Actor, error) {
 	jwtPayload, err := utilities.GetJwtPayload(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
+		return nil, account.ErrUnauthorized
+	}
+	if err := account.CheckAccountAccess(jwtPayload); err != nil {
+		return nil, err
 	}
-	homeworkParam := c.Params("id")
-	homeworkId, err := strconv.ParseUint(homeworkParam, 10, 32)
+	return find(c)
+}
+
+// signedIn returns whoever the session belongs to, admins included.
+func signedIn(c *fiber.Ctx) (*account.Actor, error) {
+	jwtPayload, err := utilities.GetJwtPayload(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homework", fiber.Map{
-			"error": errNotFound,
-		})
+		return nil, account.ErrUnauthorized
+	}
+	return account.FromClaims(jwtPayload)
+}
+
+// profilePageOf is the profile page of the actor's role.
+func profilePageOf(actor *account.Actor) string {
+	if actor.IsTeacher() {
+		return "profileTeacher"
 	}
-	homework, err := repository.Homework.GetById(uint(homeworkId))
+	return "profileStudent"
+}
+
+// currentTeacher returns the signed in teacher, forbidding students.
+func currentTeacher(c *fiber.Ctx) (*account.Actor, error) {
+	actor, err := currentActor(c)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/homeworks")
+		return nil, err
+	}
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
 	}
-	teacher, err := repository.Teacher.GetById(homework.TeacherId)
+	return actor, nil
+}
+
+// findStudent loads the student the parameter refers to; callers check the policy.
+func findStudent(param string) (*models.Student, error) {
+	studentId, err := strconv.ParseUint(param, 10, 32)
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homework", fiber.Map{
-			"error": errSomethingWrong,
-		})
+		return nil, errNotFound
 	}
-	student, err := repository.Student.GetById(homework.StudentId)
+	student, err := repository.Student.GetById(uint(studentId))
 	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homework", fiber.Map{
-			"error": errSomethingWrong,
+		return nil, errNotFound
+	}
+	return student, nil
+}
+
+// paramId parses the id route parameter, an unknown resource when it is malformed.
+func paramId(c *fiber.Ctx, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params(name), 10, 32)
+	if err != nil {
+		return 0, errNotFound
+	}
+	return uint(id), nil
+}
+
+// renderError renders the error page with the status matching err.
+func renderError(c *fiber.Ctx, err error) error {
+	logrus.WithError(err)
+	switch failure.KindOf(err) {
+	case failure.Forbidden, failure.NotFound:
+		return c.Status(utilities.StatusOf(err)).Render("error", fiber.Map{
+			"error": err,
 		})
 	}
-	role := jwtPayload["roles"].(string)
-	return c.Render("homework", fiber.Map{
+	return c.Redirect("/login")
+}
+
+// renderFailure renders the page with the reason the service failed.
+func renderFailure(c *fiber.Ctx, page string, err error) error {
+	switch failure.KindOf(err) {
+	case failure.Unauthorized, failure.Forbidden, failure.NotFound:
+		return renderError(c, err)
+	}
+	logrus.WithError(err)
+	utilities.SetRetryAfter(c, err)
+	return c.Status(utilities.StatusOf(err)).Render(page, fiber.Map{
+		"error": failureMessage(err),
+	})
+}
+
+// failureMessage hides the details of unexpected errors from the user.
+func failureMessage(err error) error {
+	if failure.KindOf(err) == failure.Internal {
+		return errSomethingWrong
+	}
+	return err
+}
+
+// homeworkPage is what the homework page shows to the actor.
+func homeworkPage(actor *account.Actor, detail *homeworks.Detail) fiber.Map {
+	homework := detail.Homework
+	status := lifecycle.State(homework.Status)
+	return fiber.Map{
 		"id":                 homework.Id,
 		"name":               homework.Name,
 		"description":        homework.Description,
@@ -511,100 +661,64 @@ func (h *homeworksHandler) Get(c *fiber.Ctx) error {
 		"maxPoints":          homework.MaxPoints,
 		"type":               homework.Type,
 		"status":             homework.Status,
-		"teacher":            teacher.Name,
-		"student":            student.Name,
-		"isTeacher":          role == Roles.Teacher,
-		"isStudentCanStart":  homework.Status == "new",
-		"isStudentCanFinish": homework.Status == "processing",
-		"isTeacherCanCheck":  homework.Status == "finished",
-		"isChecked":          homework.Status == "checked",
-	})
+		"teacher":            detail.Teacher.Name,
+		"student":            detail.Student.Name,
+		"dueAt":              homework.DueAt,
+		"submittedAt":        homework.SubmittedAt,
+		"latePenalty":        homework.LatePenalty,
+		"deadline":           detail.Deadline,
+		"attempts":           detail.Attempts,
+		"rubric":             detail.Rubric,
+		"comments":           detail.Comments,
+		"isTeacher":          actor.IsTeacher(),
+		"isAdmin":            actor.IsAdmin(),
+		"statuses":           detail.Statuses,
+		"isTeacherCanCheck":  lifecycle.Can(status, lifecycle.Checked, Roles.Teacher),
+		"isStudentCanFinish": lifecycle.Can(status, lifecycle.Finished, Roles.Student),
+		"isChecked":          status == lifecycle.Checked,
+	}
 }
 
-func (h *homeworksHandler) Update(c *fiber.Ctx) error {
-	jwtPayload, err := utilities.GetJwtPayload(c)
-	if err != nil {
-		logrus.WithError(err)
-		return c.Redirect("/login")
-	}
-	homeworkParam := c.Params("id")
-	homeworkId, err := strconv.ParseUint(homeworkParam, 10, 32)
-	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homework", fiber.Map{
-			"error": errNotFound,
-		})
-	}
-	homework, err := repository.Homework.GetById(uint(homeworkId))
-	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homework", fiber.Map{
-			"error": errNotFound,
-		})
-	}
-	role := jwtPayload["roles"].(string)
-	if role == Roles.Teacher {
-		req := forms.UpdateHomeworkTeacherRequest{}
-		if err := c.BodyParser(&req); err != nil {
-			logrus.WithError(err)
-			return c.Render("homework", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-		currentPoints, err := strconv.ParseUint(req.CurrentPoints, 10, 32)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("homework", fiber.Map{
-				"error": errPoints,
-			})
-		}
-		homework.CurrentPoints = uint8(currentPoints)
-		homework.Status = req.Status
-		student, err := repository.Student.GetById(homework.StudentId)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("homeworks", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-		mailer.CheckedHomework(student.Email, student.Name, homework.Name)
-	} else if role == Roles.Student {
-		req := forms.UpdateHomeworkStudentRequest{}
-		if err := c.BodyParser(&req); err != nil {
-			logrus.WithError(err)
-			return c.Render("homework", fiber.Map{
-				"error": errSomethingWrong,
-			})
+func newHomeworkItems(homeworks []models.Homework, types []models.HomeworkType) []homeworkItem {
+	now := time.Now()
+	// teachers may name their own types alike, so colors are looked up per teacher before the global ones
+	type typeKey struct {
+		teacherId uint
+		name      string
+	}
+	colors := make(map[typeKey]string, len(types))
+	for _, homeworkType := range types {
+		key := typeKey{name: homeworkType.Name}
+		if homeworkType.TeacherId != nil {
+			key.teacherId = *homeworkType.TeacherId
 		}
-		homework.Status = req.Status
-		teacher, err := repository.Teacher.GetById(homework.TeacherId)
-		if err != nil {
-			logrus.WithError(err)
-			return c.Render("homeworks", fiber.Map{
-				"error": errSomethingWrong,
-			})
-		}
-		mailer.CheckedHomework(teacher.Email, teacher.Name, homework.Name)
+		colors[key] = homeworkType.Color
 	}
-	err = repository.Homework.Update(homework)
-	if err != nil {
-		logrus.WithError(err)
-		return c.Render("homework", fiber.Map{
-			"error": errSomethingWrong,
+	items := make([]homeworkItem, 0, len(homeworks))
+	for _, homework := range homeworks {
+		color, ok := colors[typeKey{homework.TeacherId, homework.Type}]
+		if !ok {
+			color = colors[typeKey{name: homework.Type}]
+		}
+		items = append(items, homeworkItem{
+			Homework: homework,
+			Deadline: deadline.Check(homework.DueAt, homework.SubmittedAt, now),
+			Color:    color,
 		})
 	}
-	return c.SendStatus(fiber.StatusOK)
+	return items
 }
 
-func (h *homeworksHandler) Delete(c *fiber.Ctx) error {
-	homeworkParam := c.Params("id")
-	homeworkId, err := strconv.ParseUint(homeworkParam, 10, 32)
-	if err != nil {
-		logrus.WithError(err)
-	}
-	err = repository.Homework.Delete(uint(homeworkId))
-	if err != nil {
-		logrus.WithError(err)
+// distinctTypes keeps the first type of every name, so the filter lists each name once.
+func distinctTypes(types []models.HomeworkType) []models.HomeworkType {
+	seen := map[string]bool{}
+	distinct := []models.HomeworkType{}
+	for _, homeworkType := range types {
+		if seen[homeworkType.Name] {
+			continue
+		}
+		seen[homeworkType.Name] = true
+		distinct = append(distinct, homeworkType)
 	}
-	return c.SendStatus(fiber.StatusOK)
+	return distinct
 }
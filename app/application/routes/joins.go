@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/services/joins"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	JoinHandler       = &joinsHandler{}
+	EnrollmentHandler = &enrollmentsHandler{}
+	InviteHandler     = &invitesHandler{}
+)
+
+type (
+	joinsHandler       struct{}
+	enrollmentsHandler struct{}
+	invitesHandler     struct{}
+)
+
+// Create asks the chosen teacher to take the student into the class.
+func (h *joinsHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.CreateJoinRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("profileStudent", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if _, err := joins.Request(actor, req); err != nil {
+		return renderFailure(c, "profileStudent", err)
+	}
+	return c.Redirect("/profile")
+}
+
+// Update accepts or rejects the request.
+func (h *joinsHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.AnswerJoinRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if _, err := joins.Answer(actor, id, req); err != nil {
+		return renderFailure(c, "profileTeacher", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Update finishes the student's studies with the teacher or starts them again.
+func (h *enrollmentsHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.UpdateEnrollmentRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if _, err := joins.UpdateEnrollment(actor, id, req); err != nil {
+		return renderFailure(c, "profileTeacher", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Create makes a new invite link, so the one shared before stops working.
+func (h *invitesHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	if _, err := joins.Invite(actor); err != nil {
+		return renderFailure(c, "profileTeacher", err)
+	}
+	return c.Redirect("/profile")
+}
+
+// Join takes the student who followed the invite link into the teacher's class.
+func (h *invitesHandler) Join(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	if _, err := joins.Join(actor, c.Params("code")); err != nil {
+		return renderFailure(c, "profileStudent", err)
+	}
+	return c.Redirect("/profile")
+}
+
+func inviteLinkOf(c *fiber.Ctx, invite *models.Invite) string {
+	return c.BaseURL() + "/invites/" + invite.Code
+}
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var PasswordResetHandler = &passwordResetHandler{}
+
+type passwordResetHandler struct{}
+
+func (h *passwordResetHandler) Get(c *fiber.Ctx) error {
+	return c.Render("passwordReset", fiber.Map{})
+}
+
+// Create mails the reset link if the account exists.
+func (h *passwordResetHandler) Create(c *fiber.Ctx) error {
+	req := forms.PasswordResetRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("passwordReset", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if err := account.RequestReset(req, func(token string) string {
+		return resetLinkOf(c, token)
+	}); err != nil {
+		return renderFailure(c, "passwordReset", err)
+	}
+	return c.Render("passwordReset", fiber.Map{
+		"sent": true,
+	})
+}
+
+// GetToken shows the form for the new password the reset link leads to.
+func (h *passwordResetHandler) GetToken(c *fiber.Ctx) error {
+	return c.Render("passwordReset", fiber.Map{
+		"token": c.Params("token"),
+	})
+}
+
+// Reset sets the new password and sends the user to sign in with it.
+func (h *passwordResetHandler) Reset(c *fiber.Ctx) error {
+	token := c.Params("token")
+	req := forms.ResetPasswordRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("passwordReset", fiber.Map{
+			"error": errSomethingWrong,
+			"token": token,
+		})
+	}
+	if err := account.ResetPassword(token, req); err != nil {
+		logrus.WithError(err)
+		return c.Status(utilities.StatusOf(err)).Render("passwordReset", fiber.Map{
+			"error": failureMessage(err),
+			"token": token,
+		})
+	}
+	return c.Redirect("/login")
+}
+
+// resetLinkOf is the address of the page the token lets the user choose a new password on.
+func resetLinkOf(c *fiber.Ctx, token string) string {
+	return c.BaseURL() + "/password-reset/" + token
+}
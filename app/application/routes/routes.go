@@ -12,13 +12,68 @@ func PublicRoutes(app *fiber.App) {
 
 	app.Get("/login", LoginHandler.Get)
 	app.Post("/login", LoginHandler.Login)
+	app.Post("/login/totp", TotpHandler.Login)
+	app.Get("/login/oidc", LoginHandler.Oidc)
+	app.Get("/login/oidc/callback", LoginHandler.OidcCallback)
 	app.Delete("/login", LoginHandler.SignOut)
+
+	app.Get("/password-reset", PasswordResetHandler.Get)
+	app.Post("/password-reset", PasswordResetHandler.Create)
+	app.Get("/password-reset/:token", PasswordResetHandler.GetToken)
+	app.Post("/password-reset/:token", PasswordResetHandler.Reset)
+
+	app.Get("/verify/:token", VerificationHandler.Verify)
 }
 
 func AuthorizedRoutes(app *fiber.App) {
 	app.Get("/profile", ProfileHandler.Get)
 	app.Patch("/profile", ProfileHandler.Update)
+	app.Patch("/profile/password", ProfileHandler.UpdatePassword)
+	app.Patch("/profile/role", ProfileHandler.SwitchRole)
+	app.Post("/profile/roles", ProfileHandler.AddRole)
 	app.Delete("/profile", ProfileHandler.Delete)
+	app.Post("/profile/totp", TotpHandler.Create)
+	app.Post("/profile/totp/confirm", TotpHandler.Confirm)
+	app.Post("/profile/totp/recovery-codes", TotpHandler.CreateRecoveryCodes)
+	app.Delete("/profile/totp", TotpHandler.Delete)
+	app.Post("/profile/tokens", ApiTokenHandler.Create)
+	app.Delete("/profile/tokens/:id", ApiTokenHandler.Delete)
+	app.Post("/verification", VerificationHandler.Create)
+
+	app.Get("/sessions", SessionHandler.GetList)
+	app.Delete("/sessions", SessionHandler.DeleteAll)
+	app.Delete("/sessions/:id", SessionHandler.Delete)
+
+	app.Post("/joins", JoinHandler.Create)
+	app.Patch("/joins/:id", JoinHandler.Update)
+	app.Patch("/enrollments/:id", EnrollmentHandler.Update)
+	app.Post("/invites", InviteHandler.Create)
+	app.Get("/invites/:code", InviteHandler.Join)
+
+	app.Get("/gradebook", GradebookHandler.Get)
+
+	app.Post("/groups", GroupHandler.Create)
+	app.Delete("/groups/:id", GroupHandler.Delete)
+	app.Post("/groups/:id/students", GroupHandler.AddStudent)
+	app.Delete("/groups/:id/students/:studentId", GroupHandler.RemoveStudent)
+
+	app.Get("/types", TypeHandler.GetList)
+	app.Post("/types", TypeHandler.Create)
+	app.Patch("/types/:id", TypeHandler.Update)
+	app.Delete("/types/:id", TypeHandler.Delete)
+
+	app.Get("/rubrics", RubricHandler.GetList)
+	app.Post("/rubrics", RubricHandler.Create)
+	app.Delete("/rubrics/:id", RubricHandler.Delete)
+
+	app.Get("/templates", TemplateHandler.GetList)
+	app.Post("/templates", TemplateHandler.Create)
+	app.Get("/templates/export", TemplateHandler.Export)
+	app.Post("/templates/import", TemplateHandler.Import)
+	app.Get("/templates/:id", TemplateHandler.Get)
+	app.Patch("/templates/:id", TemplateHandler.Update)
+	app.Delete("/templates/:id", TemplateHandler.Delete)
+	app.Post("/templates/:id/clone", TemplateHandler.Clone)
 
 	app.Get("/homeworks", HomeworkHandler.GetList)
 	app.Post("/homeworks", HomeworkHandler.Create)
@@ -26,4 +81,18 @@ func AuthorizedRoutes(app *fiber.App) {
 	app.Get("/homeworks/:id", HomeworkHandler.Get)
 	app.Patch("/homeworks/:id", HomeworkHandler.Update)
 	app.Delete("/homeworks/:id", HomeworkHandler.Delete)
+
+	app.Get("/homeworks/:id/files/:fileId", SubmissionHandler.GetFile)
+
+	app.Post("/homeworks/:id/comments", CommentHandler.Create)
+
+	app.Get("/admin", AdminHandler.Get)
+	app.Patch("/admin/totp", AdminHandler.UpdateTotp)
+	app.Patch("/admin/teachers/:id", AdminHandler.UpdateTeacher)
+	app.Patch("/admin/students/:id", AdminHandler.UpdateStudent)
+	app.Get("/admin/students/:id", AdminHandler.GetStudent)
+	app.Patch("/admin/enrollments/:id", AdminHandler.UpdateEnrollment)
+	app.Get("/admin/homeworks/:id", AdminHandler.GetHomework)
+	app.Get("/admin/homeworks/:id/files/:fileId", AdminHandler.GetFile)
+	app.Get("/admin/audit", AdminHandler.GetAudit)
 }
@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/grading"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var RubricHandler = &rubricsHandler{}
+
+type rubricsHandler struct{}
+
+func (h *rubricsHandler) GetList(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	rubrics, err := repository.Rubric.GetByTeacherId(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("rubrics", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Render("rubrics", fiber.Map{
+		"rubrics": *rubrics,
+	})
+}
+
+func (h *rubricsHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.CreateRubricRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("rubrics", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	err = initializers.Validator.Struct(req)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("rubrics", fiber.Map{
+			"error": errValidation,
+		})
+	}
+	criteria, err := parseCriteria(req.Criteria)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Status(fiber.StatusUnprocessableEntity).Render("rubrics", fiber.Map{
+			"error": err,
+		})
+	}
+	err = repository.Rubric.Create(&models.Rubric{
+		TeacherId: actor.Teacher.Id,
+		Name:      req.Name,
+		Criteria:  criteria,
+	})
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("rubrics", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Redirect("/rubrics")
+}
+
+func (h *rubricsHandler) Delete(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	rubric, err := h.rubric(actor, c.Params("id"), policy.Delete)
+	if err != nil {
+		return renderError(c, err)
+	}
+	err = repository.Rubric.Delete(rubric)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("rubrics", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *rubricsHandler) rubric(actor *account.Actor, param string, action policy.Action) (*models.Rubric, error) {
+	rubricId, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		return nil, errNotFound
+	}
+	rubric, err := repository.Rubric.GetById(uint(rubricId))
+	if err != nil {
+		return nil, errNotFound
+	}
+	if err := policy.Rubric(actor, action, rubric); err != nil {
+		return nil, err
+	}
+	return rubric, nil
+}
+
+// parseCriteria reads one "name: max points" criterion per line. The total has to fit the points columns too.
+func parseCriteria(text string) ([]models.RubricCriterion, error) {
+	criteria := []models.RubricCriterion{}
+	total := 0.0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		separator := strings.LastIndex(line, ":")
+		if separator < 0 {
+			return nil, errCriteria
+		}
+		name := strings.TrimSpace(line[:separator])
+		maxPoints, err := grading.Parse(line[separator+1:])
+		if name == "" || err != nil || maxPoints == 0 {
+			return nil, errCriteria
+		}
+		criteria = append(criteria, models.RubricCriterion{
+			Name:      name,
+			MaxPoints: maxPoints,
+			Position:  len(criteria),
+		})
+		total += maxPoints
+	}
+	if len(criteria) == 0 {
+		return nil, errCriteria
+	}
+	if grading.Round(total) > grading.Limit {
+		return nil, grading.ErrTotal
+	}
+	return criteria, nil
+}
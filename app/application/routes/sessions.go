@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+var SessionHandler = &sessionsHandler{}
+
+type sessionsHandler struct{}
+
+// GetList shows the devices the account is signed in on.
+func (h *sessionsHandler) GetList(c *fiber.Ctx) error {
+	actor, err := managing(c, signedIn)
+	if err != nil {
+		return renderError(c, err)
+	}
+	sessions, err := account.Sessions(actor)
+	if err != nil {
+		return renderFailure(c, "sessions", err)
+	}
+	return c.Render("sessions", fiber.Map{
+		"sessions": sessions,
+		"current":  actor.SessionId,
+	})
+}
+
+// Delete signs the account out on one device, this one included.
+func (h *sessionsHandler) Delete(c *fiber.Ctx) error {
+	actor, err := managing(c, signedIn)
+	if err != nil {
+		return renderError(c, err)
+	}
+	id, err := paramId(c, "id")
+	if err != nil {
+		return renderError(c, err)
+	}
+	if err := account.RevokeSession(actor, id); err != nil {
+		return renderFailure(c, "sessions", err)
+	}
+	if id == actor.SessionId {
+		endSession(c)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// DeleteAll signs the account out everywhere.
+func (h *sessionsHandler) DeleteAll(c *fiber.Ctx) error {
+	actor, err := managing(c, signedIn)
+	if err != nil {
+		return renderError(c, err)
+	}
+	if err := account.SignOutEverywhere(actor); err != nil {
+		return renderFailure(c, "sessions", err)
+	}
+	endSession(c)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// CheckSession lets the request in while the session of its token has not been revoked.
+func CheckSession(c *fiber.Ctx) error {
+	claims, err := utilities.GetJwtPayload(c)
+	if err == nil {
+		err = account.CheckSession(claims)
+	}
+	if err != nil {
+		return RefreshSession(c, err)
+	}
+	return c.Next()
+}
+
+// RefreshSession renews the expired access token with the refresh cookie and lets the request in,
+// or sends the user to sign in when the session has ended.
+func RefreshSession(c *fiber.Ctx, err error) error {
+	refresh := c.Cookies(initializers.Cfg.RefreshCookieKey)
+	if refresh == "" {
+		return c.Redirect("/login")
+	}
+	_, tokens, err := account.Refresh(refresh, deviceOf(c))
+	if err != nil {
+		logrus.WithError(err)
+		endSession(c)
+		return c.Redirect("/login")
+	}
+	token, err := jwt.Parse(tokens.Access, func(t *jwt.Token) (interface{}, error) {
+		return []byte(initializers.Cfg.JwtSecretKey), nil
+	})
+	if err != nil {
+		logrus.WithError(err)
+		return c.Redirect("/login")
+	}
+	setSessionCookies(c, tokens)
+	c.Locals(initializers.Cfg.ContextKeyUser, token)
+	return c.Next()
+}
+
+// startSession signs the actor in on this device with new session cookies.
+func startSession(c *fiber.Ctx, actor *account.Actor) error {
+	tokens, err := account.StartSession(actor, deviceOf(c))
+	if err != nil {
+		return err
+	}
+	setSessionCookies(c, tokens)
+	return nil
+}
+
+// endSession forgets the session cookies of this device.
+func endSession(c *fiber.Ctx) {
+	c.ClearCookie(initializers.Cfg.JwtCookieKey, initializers.Cfg.RefreshCookieKey)
+}
+
+func setSessionCookies(c *fiber.Ctx, tokens *account.Tokens) {
+	c.Cookie(&fiber.Cookie{
+		Name:     initializers.Cfg.JwtCookieKey,
+		Value:    tokens.Access,
+		Expires:  tokens.AccessExpiresAt,
+		HTTPOnly: true,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     initializers.Cfg.RefreshCookieKey,
+		Value:    tokens.Refresh,
+		Expires:  tokens.RefreshExpiresAt,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+}
+
+// deviceOf describes where the request comes from.
+func deviceOf(c *fiber.Ctx) account.Device {
+	return account.Device{UserAgent: c.Get(fiber.HeaderUserAgent), Ip: c.IP()}
+}
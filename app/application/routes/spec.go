@@ -0,0 +1,276 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	userAccess = []string{openapi.CookieAuth, openapi.ApiTokenAuth}
+	// cookieAuth is for managing the account, which personal access tokens cannot do.
+	cookieAuth = []string{openapi.CookieAuth}
+	signIn     = openapi.Redirect("Redirect to the sign in page when the session is missing or expired")
+	forbidden  = openapi.Page(fiber.StatusForbidden, "The page belongs to another account or role, the email is not confirmed yet, two-factor authentication is not set up or a personal access token cannot manage the account")
+	notFound   = openapi.Page(fiber.StatusNotFound, "The page does not exist")
+	invalid    = openapi.Page(fiber.StatusUnprocessableEntity, "The page with the validation error")
+	ok         = openapi.Empty(fiber.StatusOK, "Done; the page reloads itself")
+	// tooManyAttempts answers sign ins while the account or the IP address waits after failing, with a Retry-After header.
+	tooManyAttempts = openapi.Page(fiber.StatusTooManyRequests, "Too many failed attempts; try again after the time the page tells")
+)
+
+// Operations describes the routes registered by PublicRoutes and AuthorizedRoutes.
+var Operations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: "/", Tag: "pages", Summary: "Main page",
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The main page")}},
+
+	{Method: fiber.MethodGet, Path: "/registration", Tag: "auth", Summary: "Registration page",
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The registration form")}},
+	{Method: fiber.MethodPost, Path: "/registration", Tag: "auth", Summary: "Register a user who teaches or studies; the other role can be added later",
+		Body: forms.RegistrateRequest{},
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the sign in page"),
+			openapi.Page(fiber.StatusConflict, "The email is taken"),
+			invalid,
+		}},
+	{Method: fiber.MethodGet, Path: "/login", Tag: "auth", Summary: "Sign in page",
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The sign in form")}},
+	{Method: fiber.MethodPost, Path: "/login", Tag: "auth", Summary: "Sign in and set the session cookie",
+		Body: forms.LoginRequest{},
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the profile of the teaching role, or of the studying one, or to the admin console for admins"),
+			openapi.Page(fiber.StatusOK, "The form asking users with two-factor authentication for a code"),
+			openapi.Page(fiber.StatusUnauthorized, "The email or password is incorrect"),
+			openapi.Page(fiber.StatusForbidden, "Every role of the account is deactivated"),
+			invalid,
+			tooManyAttempts,
+		}},
+	{Method: fiber.MethodPost, Path: "/login/totp", Tag: "auth", Summary: "Finish signing in with a code of the authenticator app or a recovery code",
+		Body: forms.TotpLoginRequest{},
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the profile"),
+			openapi.Page(fiber.StatusUnauthorized, "Signing in took too long"),
+			openapi.Page(fiber.StatusUnprocessableEntity, "The code is incorrect or has been used already"),
+			tooManyAttempts,
+		}},
+	{Method: fiber.MethodGet, Path: "/login/oidc", Tag: "auth", Summary: "Sign in at the OpenID Connect identity provider",
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the sign in page of the identity provider"),
+			openapi.Page(fiber.StatusNotFound, "Signing in with an identity provider is not set up"),
+		}},
+	{Method: fiber.MethodGet, Path: "/login/oidc/callback", Tag: "auth", Summary: "Finish signing in when the identity provider sends the user back",
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the profile"),
+			openapi.Page(fiber.StatusOK, "The form asking users with two-factor authentication for a code"),
+			openapi.Page(fiber.StatusUnauthorized, "Signing in took too long, was started elsewhere or the provider refused it"),
+			openapi.Page(fiber.StatusForbidden, "The provider has not confirmed the email or gives no role, or every role is deactivated"),
+			notFound,
+		}},
+	{Method: fiber.MethodDelete, Path: "/login", Tag: "auth", Summary: "End the session and clear its cookies",
+		Responses: []openapi.Response{openapi.Empty(fiber.StatusOK, "Signed out")}},
+	{Method: fiber.MethodGet, Path: "/password-reset", Tag: "auth", Summary: "Forgotten password page",
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The form to ask for a reset link")}},
+	{Method: fiber.MethodPost, Path: "/password-reset", Tag: "auth", Summary: "Mail a one-time password reset link",
+		Body: forms.PasswordResetRequest{},
+		Responses: []openapi.Response{
+			openapi.Page(fiber.StatusOK, "The link is mailed if the account exists"),
+			invalid,
+			openapi.Page(fiber.StatusTooManyRequests, "Too many links were asked for the email"),
+		}},
+	{Method: fiber.MethodGet, Path: "/password-reset/:token", Tag: "auth", Summary: "New password page the reset link leads to",
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The form for the new password")}},
+	{Method: fiber.MethodPost, Path: "/password-reset/:token", Tag: "auth", Summary: "Set a new password with the mailed token",
+		Body: forms.ResetPasswordRequest{},
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the sign in page"),
+			openapi.Page(fiber.StatusUnprocessableEntity, "The link is invalid, used or expired, or the password is invalid"),
+		}},
+
+	{Method: fiber.MethodGet, Path: "/verify/:token", Tag: "auth", Summary: "Confirm the email with the mailed link",
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the profile"),
+			openapi.Page(fiber.StatusUnprocessableEntity, "The link is invalid or expired"),
+		}},
+
+	{Method: fiber.MethodGet, Path: "/profile", Tag: "profile", Summary: "Profile page", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The teacher or student profile"), signIn}},
+	{Method: fiber.MethodPatch, Path: "/profile", Tag: "profile", Summary: "Change the name and email; a new email needs the current password, has to be confirmed again and signs out the other sessions", Security: cookieAuth,
+		Body:      forms.UpdateProfileRequest{},
+		Responses: []openapi.Response{ok, openapi.Page(fiber.StatusConflict, "The email is taken"), openapi.Page(fiber.StatusUnprocessableEntity, "The data is invalid or the current password is incorrect"), forbidden, signIn}},
+	{Method: fiber.MethodPatch, Path: "/profile/password", Tag: "profile", Summary: "Change the password and sign out the other sessions", Security: cookieAuth,
+		Body:      forms.ChangePasswordRequest{},
+		Responses: []openapi.Response{ok, openapi.Page(fiber.StatusUnprocessableEntity, "The current password is incorrect or the new one is invalid"), forbidden, signIn}},
+	{Method: fiber.MethodPatch, Path: "/profile/role", Tag: "profile", Summary: "Act in the other role of the user on this device", Security: cookieAuth,
+		Body: forms.RoleRequest{},
+		Responses: []openapi.Response{
+			ok,
+			openapi.Page(fiber.StatusForbidden, "The role is deactivated or a personal access token cannot switch it"),
+			openapi.Page(fiber.StatusUnprocessableEntity, "The user does not have the role yet"),
+			signIn,
+		}},
+	{Method: fiber.MethodPost, Path: "/profile/roles", Tag: "profile", Summary: "Add the other role to the user and act in it on this device", Security: cookieAuth,
+		Body:      forms.RoleRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the profile of the new role"), openapi.Page(fiber.StatusConflict, "The user has the role already"), invalid, forbidden, signIn}},
+	{Method: fiber.MethodPost, Path: "/verification", Tag: "profile", Summary: "Mail the link confirming the email again", Security: userAccess,
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the profile"), openapi.Page(fiber.StatusConflict, "The email is already confirmed"), signIn}},
+	{Method: fiber.MethodDelete, Path: "/profile", Tag: "profile", Summary: "Delete the account with its homework", Security: cookieAuth,
+		Responses: []openapi.Response{ok, forbidden, signIn}},
+	{Method: fiber.MethodPost, Path: "/profile/totp", Tag: "profile", Summary: "Start setting up two-factor authentication with a new secret", Security: cookieAuth,
+		Responses: []openapi.Response{
+			openapi.Page(fiber.StatusOK, "The secret and its provisioning URI for the authenticator app"),
+			openapi.Page(fiber.StatusConflict, "Two-factor authentication is already on"),
+			forbidden, signIn,
+		}},
+	{Method: fiber.MethodPost, Path: "/profile/totp/confirm", Tag: "profile", Summary: "Turn two-factor authentication on with a code of the authenticator app", Security: cookieAuth,
+		Body: forms.TotpCodeRequest{},
+		Responses: []openapi.Response{
+			openapi.Page(fiber.StatusOK, "The recovery codes, shown this once"),
+			openapi.Page(fiber.StatusConflict, "Two-factor authentication is already on or not started"),
+			openapi.Page(fiber.StatusUnprocessableEntity, "The code is incorrect"),
+			forbidden, signIn,
+		}},
+	{Method: fiber.MethodPost, Path: "/profile/totp/recovery-codes", Tag: "profile", Summary: "Replace the recovery codes", Security: cookieAuth,
+		Body: forms.TotpCodeRequest{},
+		Responses: []openapi.Response{
+			openapi.Page(fiber.StatusOK, "The new recovery codes, shown this once"),
+			openapi.Page(fiber.StatusConflict, "Two-factor authentication is off"),
+			openapi.Page(fiber.StatusUnprocessableEntity, "The code is incorrect or has been used already"),
+			forbidden, signIn,
+		}},
+	{Method: fiber.MethodDelete, Path: "/profile/totp", Tag: "profile", Summary: "Turn two-factor authentication off", Security: cookieAuth,
+		Body:      forms.TotpCodeRequest{},
+		Responses: []openapi.Response{ok, openapi.Page(fiber.StatusConflict, "Two-factor authentication is off"), invalid, forbidden, signIn}},
+	{Method: fiber.MethodPost, Path: "/profile/tokens", Tag: "profile", Summary: "Make a personal access token acting in the current role", Security: cookieAuth,
+		Body: forms.ApiTokenRequest{},
+		Responses: []openapi.Response{
+			openapi.Page(fiber.StatusCreated, "The token, shown this once"),
+			invalid, forbidden, signIn,
+		}},
+	{Method: fiber.MethodDelete, Path: "/profile/tokens/:id", Tag: "profile", Summary: "Revoke a personal access token", Security: cookieAuth,
+		Responses: []openapi.Response{ok, notFound, forbidden, signIn}},
+	{Method: fiber.MethodGet, Path: "/sessions", Tag: "profile", Summary: "The devices the account is signed in on", Security: cookieAuth,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The active sessions"), forbidden, signIn}},
+	{Method: fiber.MethodDelete, Path: "/sessions", Tag: "profile", Summary: "Sign out everywhere", Security: cookieAuth,
+		Responses: []openapi.Response{ok, forbidden, signIn}},
+	{Method: fiber.MethodDelete, Path: "/sessions/:id", Tag: "profile", Summary: "Sign out on one device", Security: cookieAuth,
+		Responses: []openapi.Response{ok, notFound, forbidden, signIn}},
+
+	{Method: fiber.MethodPost, Path: "/joins", Tag: "profile", Summary: "Ask a teacher to take the student into the class", Security: userAccess,
+		Body: forms.CreateJoinRequest{},
+		Responses: []openapi.Response{
+			openapi.Redirect("Redirect to the profile"),
+			openapi.Page(fiber.StatusConflict, "The student already studies with or waits for the teacher"),
+			invalid, forbidden, signIn,
+		}},
+	{Method: fiber.MethodPatch, Path: "/joins/:id", Tag: "profile", Summary: "Accept or reject a join request", Security: userAccess,
+		Body:      forms.AnswerJoinRequest{},
+		Responses: []openapi.Response{ok, openapi.Page(fiber.StatusConflict, "The request has already been answered"), invalid, forbidden, notFound, signIn}},
+	{Method: fiber.MethodPatch, Path: "/enrollments/:id", Tag: "profile", Summary: "Finish the student's studies with the teacher or start them again", Security: userAccess,
+		Body:      forms.UpdateEnrollmentRequest{},
+		Responses: []openapi.Response{ok, invalid, forbidden, notFound, signIn}},
+	{Method: fiber.MethodPost, Path: "/invites", Tag: "profile", Summary: "Make a new invite link; the previous one stops working", Security: userAccess,
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the profile"), forbidden, signIn}},
+	{Method: fiber.MethodGet, Path: "/invites/:code", Tag: "profile", Summary: "Join the class of the teacher who shared the link", Security: userAccess,
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the profile"), forbidden, notFound, signIn}},
+
+	{Method: fiber.MethodGet, Path: "/gradebook", Tag: "gradebook", Summary: "Teacher gradebook", Security: userAccess,
+		Query:     []openapi.Parameter{{Name: "student", Description: "Id of the student whose trend is shown"}},
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "Grades, progress and trend of the teacher's students"), forbidden, notFound, signIn}},
+
+	{Method: fiber.MethodPost, Path: "/groups", Tag: "groups", Summary: "Create a group", Security: userAccess,
+		Body:      forms.CreateGroupRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the profile"), forbidden, signIn}},
+	{Method: fiber.MethodDelete, Path: "/groups/:id", Tag: "groups", Summary: "Delete a group", Security: userAccess,
+		Responses: []openapi.Response{ok, forbidden, notFound, signIn}},
+	{Method: fiber.MethodPost, Path: "/groups/:id/students", Tag: "groups", Summary: "Add a student to a group", Security: userAccess,
+		Body:      forms.GroupStudentRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the profile"), forbidden, notFound, signIn}},
+	{Method: fiber.MethodDelete, Path: "/groups/:id/students/:studentId", Tag: "groups", Summary: "Remove a student from a group", Security: userAccess,
+		Responses: []openapi.Response{ok, forbidden, notFound, signIn}},
+
+	{Method: fiber.MethodGet, Path: "/types", Tag: "types", Summary: "Homework types page", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The global and the teacher's homework types"), forbidden, signIn}},
+	{Method: fiber.MethodPost, Path: "/types", Tag: "types", Summary: "Create a homework type", Security: userAccess,
+		Body:      forms.HomeworkTypeRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the types page"), openapi.Page(fiber.StatusConflict, "The name is taken"), forbidden, signIn}},
+	{Method: fiber.MethodPatch, Path: "/types/:id", Tag: "types", Summary: "Update a homework type", Security: userAccess,
+		Body:      forms.HomeworkTypeRequest{},
+		Responses: []openapi.Response{ok, openapi.Page(fiber.StatusConflict, "The name is taken"), forbidden, notFound, signIn}},
+	{Method: fiber.MethodDelete, Path: "/types/:id", Tag: "types", Summary: "Delete a homework type", Security: userAccess,
+		Responses: []openapi.Response{ok, forbidden, notFound, signIn}},
+
+	{Method: fiber.MethodGet, Path: "/rubrics", Tag: "rubrics", Summary: "Rubrics page", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The teacher's rubrics"), forbidden, signIn}},
+	{Method: fiber.MethodPost, Path: "/rubrics", Tag: "rubrics", Summary: "Create a rubric", Security: userAccess,
+		Body:      forms.CreateRubricRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the rubrics page"), invalid, forbidden, signIn}},
+	{Method: fiber.MethodDelete, Path: "/rubrics/:id", Tag: "rubrics", Summary: "Delete a rubric", Security: userAccess,
+		Responses: []openapi.Response{ok, forbidden, notFound, signIn}},
+
+	{Method: fiber.MethodGet, Path: "/templates", Tag: "templates", Summary: "Homework templates page", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The teacher's templates"), forbidden, signIn}},
+	{Method: fiber.MethodPost, Path: "/templates", Tag: "templates", Summary: "Create a template", Security: userAccess,
+		Body:      forms.HomeworkTemplateRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the templates page"), invalid, forbidden, signIn}},
+	{Method: fiber.MethodGet, Path: "/templates/export", Tag: "templates", Summary: "Download the teacher's templates", Security: userAccess,
+		Responses: []openapi.Response{openapi.JSON(fiber.StatusOK, "The templates as a JSON attachment", []forms.HomeworkTemplate{}), forbidden, signIn}},
+	{Method: fiber.MethodPost, Path: "/templates/import", Tag: "templates", Summary: "Import templates from JSON", Security: userAccess,
+		Body: []forms.HomeworkTemplate{}, Files: []string{"file"},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the templates page"), invalid, forbidden, signIn}},
+	{Method: fiber.MethodGet, Path: "/templates/:id", Tag: "templates", Summary: "Template page", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The template edit form"), forbidden, notFound, signIn}},
+	{Method: fiber.MethodPatch, Path: "/templates/:id", Tag: "templates", Summary: "Update a template", Security: userAccess,
+		Body:      forms.HomeworkTemplateRequest{},
+		Responses: []openapi.Response{ok, invalid, forbidden, notFound, signIn}},
+	{Method: fiber.MethodDelete, Path: "/templates/:id", Tag: "templates", Summary: "Delete a template", Security: userAccess,
+		Responses: []openapi.Response{ok, forbidden, notFound, signIn}},
+	{Method: fiber.MethodPost, Path: "/templates/:id/clone", Tag: "templates", Summary: "Copy a template", Security: userAccess,
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the templates page"), forbidden, notFound, signIn}},
+
+	{Method: fiber.MethodGet, Path: "/homeworks", Tag: "homeworks", Summary: "Homework page", Security: userAccess,
+		Query: []openapi.Parameter{
+			{Name: "sort", Description: "urgency to show unfinished homework by due date first"},
+			{Name: "type", Description: "Show only homework of this type"},
+			{Name: "template", Description: "Id of the template the new homework form is filled from"},
+		},
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The homework the teacher gives or the student does"), signIn}},
+	{Method: fiber.MethodPost, Path: "/homeworks", Tag: "homeworks", Summary: "Give homework to students or a group", Security: userAccess,
+		Body:      forms.CreateHomeworkRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the homework page"), invalid, forbidden, signIn}},
+	{Method: fiber.MethodGet, Path: "/homeworks/:id", Tag: "homeworks", Summary: "Homework page", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The homework with its attempts and comments"), forbidden, notFound, signIn}},
+	{Method: fiber.MethodPatch, Path: "/homeworks/:id", Tag: "homeworks", Summary: "Change the homework status", Security: userAccess,
+		Body: forms.UpdateHomeworkRequest{}, Files: []string{"files"},
+		Responses: []openapi.Response{ok, openapi.Page(fiber.StatusConflict, "The status cannot be changed this way"), invalid, forbidden, notFound, signIn}},
+	{Method: fiber.MethodDelete, Path: "/homeworks/:id", Tag: "homeworks", Summary: "Delete homework", Security: userAccess,
+		Responses: []openapi.Response{ok, forbidden, notFound, signIn}},
+	{Method: fiber.MethodGet, Path: "/homeworks/:id/files/:fileId", Tag: "homeworks", Summary: "Download a submitted file", Security: userAccess,
+		Responses: []openapi.Response{openapi.File("The file"), openapi.Empty(fiber.StatusNotFound, "The file does not exist"), signIn}},
+	{Method: fiber.MethodPost, Path: "/homeworks/:id/comments", Tag: "homeworks", Summary: "Comment on homework", Security: userAccess,
+		Body:      forms.CreateCommentRequest{},
+		Responses: []openapi.Response{openapi.Redirect("Redirect to the homework page"), forbidden, notFound, signIn}},
+
+	{Method: fiber.MethodGet, Path: "/admin", Tag: "admin", Summary: "Admin console", Security: userAccess,
+		Query:     []openapi.Parameter{{Name: "q", Description: "Show only the accounts whose name or email contains it"}},
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The teachers and students"), forbidden, signIn}},
+	{Method: fiber.MethodPatch, Path: "/admin/totp", Tag: "admin", Summary: "Make two-factor authentication mandatory for teachers or leave it to them", Security: userAccess,
+		Body:      forms.TotpPolicyRequest{},
+		Responses: []openapi.Response{ok, invalid, forbidden, signIn}},
+	{Method: fiber.MethodPatch, Path: "/admin/teachers/:id", Tag: "admin", Summary: "Deactivate or reactivate a teacher", Security: userAccess,
+		Body:      forms.AccountStatusRequest{},
+		Responses: []openapi.Response{ok, invalid, forbidden, notFound, signIn}},
+	{Method: fiber.MethodPatch, Path: "/admin/students/:id", Tag: "admin", Summary: "Deactivate or reactivate a student", Security: userAccess,
+		Body:      forms.AccountStatusRequest{},
+		Responses: []openapi.Response{ok, invalid, forbidden, notFound, signIn}},
+	{Method: fiber.MethodGet, Path: "/admin/students/:id", Tag: "admin", Summary: "Student page of the admin console", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The student's teachers and homework"), forbidden, notFound, signIn}},
+	{Method: fiber.MethodPatch, Path: "/admin/enrollments/:id", Tag: "admin", Summary: "Move the enrolled student to another teacher", Security: userAccess,
+		Body:      forms.ReassignRequest{},
+		Responses: []openapi.Response{ok, openapi.Page(fiber.StatusConflict, "The student already studies with this teacher"), invalid, forbidden, notFound, signIn}},
+	{Method: fiber.MethodGet, Path: "/admin/homeworks/:id", Tag: "admin", Summary: "Homework page of the admin console", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The homework with its attempts and comments"), forbidden, notFound, signIn}},
+	{Method: fiber.MethodGet, Path: "/admin/homeworks/:id/files/:fileId", Tag: "admin", Summary: "Download a submitted file", Security: userAccess,
+		Responses: []openapi.Response{openapi.File("The file"), openapi.Empty(fiber.StatusNotFound, "The file does not exist"), signIn}},
+	{Method: fiber.MethodGet, Path: "/admin/audit", Tag: "admin", Summary: "Audit trail of the admin actions", Security: userAccess,
+		Responses: []openapi.Response{openapi.Page(fiber.StatusOK, "The latest admin actions"), forbidden, signIn}},
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/services/homeworks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var SubmissionHandler = &submissionsHandler{}
+
+type submissionsHandler struct{}
+
+func (h *submissionsHandler) GetFile(c *fiber.Ctx) error {
+	actor, err := currentActor(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	homeworkId, err := paramId(c, "id")
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	fileId, err := paramId(c, "fileId")
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	file, reader, err := homeworks.File(actor, homeworkId, fileId)
+	if err != nil {
+		logrus.WithError(err)
+		return c.SendStatus(utilities.StatusOf(err))
+	}
+	c.Attachment(file.Name)
+	return c.SendStream(reader)
+}
@@ -0,0 +1,307 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/grading"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var TemplateHandler = &templatesHandler{}
+
+var errImport = errors.New("the file does not contain valid homework templates")
+
+type templatesHandler struct{}
+
+func (h *templatesHandler) GetList(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	templates, err := repository.HomeworkTemplate.GetByTeacherId(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	types, err := repository.HomeworkType.GetAvailable(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Render("templates", fiber.Map{
+		"templates": *templates,
+		"types":     *types,
+	})
+}
+
+func (h *templatesHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	template := &models.HomeworkTemplate{TeacherId: actor.Teacher.Id}
+	if err := h.fill(c, template); err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": err,
+		})
+	}
+	err = repository.HomeworkTemplate.Create(template)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Redirect("/templates")
+}
+
+func (h *templatesHandler) Get(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	template, err := h.template(actor, c.Params("id"), policy.View)
+	if err != nil {
+		return renderError(c, err)
+	}
+	types, err := repository.HomeworkType.GetAvailable(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("template", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Render("template", fiber.Map{
+		"template": template,
+		"types":    *types,
+	})
+}
+
+func (h *templatesHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	template, err := h.template(actor, c.Params("id"), policy.Update)
+	if err != nil {
+		return renderError(c, err)
+	}
+	if err := h.fill(c, template); err != nil {
+		logrus.WithError(err)
+		return c.Render("template", fiber.Map{
+			"error": err,
+		})
+	}
+	err = repository.HomeworkTemplate.Update(template)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("template", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *templatesHandler) Clone(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	template, err := h.template(actor, c.Params("id"), policy.View)
+	if err != nil {
+		return renderError(c, err)
+	}
+	err = repository.HomeworkTemplate.Create(&models.HomeworkTemplate{
+		TeacherId:   actor.Teacher.Id,
+		Name:        fmt.Sprintf("%s (copy)", template.Name),
+		Description: template.Description,
+		MaxPoints:   template.MaxPoints,
+		Type:        template.Type,
+	})
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Redirect("/templates")
+}
+
+func (h *templatesHandler) Delete(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	template, err := h.template(actor, c.Params("id"), policy.Delete)
+	if err != nil {
+		return renderError(c, err)
+	}
+	err = repository.HomeworkTemplate.Delete(template)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *templatesHandler) Export(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	templates, err := repository.HomeworkTemplate.GetByTeacherId(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	exported := make([]forms.HomeworkTemplate, 0, len(*templates))
+	for _, template := range *templates {
+		exported = append(exported, forms.HomeworkTemplate{
+			Name:        template.Name,
+			Description: template.Description,
+			MaxPoints:   template.MaxPoints,
+			Type:        template.Type,
+		})
+	}
+	c.Attachment("homework-templates.json")
+	return c.JSON(exported)
+}
+
+// Import accepts templates as an uploaded JSON file or as the JSON request body.
+func (h *templatesHandler) Import(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	body := c.Body()
+	if header, err := c.FormFile("file"); err == nil {
+		file, err := header.Open()
+		if err != nil {
+			logrus.WithError(err)
+			return c.Render("templates", fiber.Map{
+				"error": errSomethingWrong,
+			})
+		}
+		defer file.Close()
+		body, err = io.ReadAll(file)
+		if err != nil {
+			logrus.WithError(err)
+			return c.Render("templates", fiber.Map{
+				"error": errSomethingWrong,
+			})
+		}
+	}
+	imported := []forms.HomeworkTemplate{}
+	if err := json.Unmarshal(body, &imported); err != nil || len(imported) == 0 {
+		logrus.WithError(err)
+		return c.Status(fiber.StatusUnprocessableEntity).Render("templates", fiber.Map{
+			"error": errImport,
+		})
+	}
+	for _, template := range imported {
+		if err := initializers.Validator.Struct(template); err != nil {
+			logrus.WithError(err)
+			return c.Status(fiber.StatusUnprocessableEntity).Render("templates", fiber.Map{
+				"error": errImport,
+			})
+		}
+	}
+	templates := make([]models.HomeworkTemplate, 0, len(imported))
+	types := []models.HomeworkType{}
+	for _, template := range imported {
+		if homeworkType := h.newType(actor.Teacher, template, types); homeworkType != nil {
+			types = append(types, *homeworkType)
+		}
+		templates = append(templates, models.HomeworkTemplate{
+			TeacherId:   actor.Teacher.Id,
+			Name:        template.Name,
+			Description: template.Description,
+			MaxPoints:   template.MaxPoints,
+			Type:        template.Type,
+		})
+	}
+	err = repository.HomeworkTemplate.CreateMany(&templates, &types)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("templates", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Redirect("/templates")
+}
+
+func (h *templatesHandler) template(actor *account.Actor, param string, action policy.Action) (*models.HomeworkTemplate, error) {
+	templateId, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		return nil, errNotFound
+	}
+	template, err := repository.HomeworkTemplate.GetById(uint(templateId))
+	if err != nil {
+		return nil, errNotFound
+	}
+	if err := policy.Template(actor, action, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// newType returns the imported type the teacher does not have yet, nil when they have it or it is already among the types to create.
+func (h *templatesHandler) newType(teacher *models.Teacher, template forms.HomeworkTemplate, types []models.HomeworkType) *models.HomeworkType {
+	for _, homeworkType := range types {
+		if homeworkType.Name == template.Type {
+			return nil
+		}
+	}
+	if _, err := repository.HomeworkType.GetByName(teacher.Id, template.Type); err == nil {
+		return nil
+	}
+	return &models.HomeworkType{
+		TeacherId:        &teacher.Id,
+		Name:             template.Type,
+		Color:            models.DefaultTypeColor,
+		DefaultMaxPoints: template.MaxPoints,
+	}
+}
+
+// fill copies the validated request into the template.
+func (h *templatesHandler) fill(c *fiber.Ctx, template *models.HomeworkTemplate) error {
+	req := forms.HomeworkTemplateRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return errSomethingWrong
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return errValidation
+	}
+	maxPoints, err := grading.Parse(req.MaxPoints)
+	if err != nil {
+		return err
+	}
+	if _, err := repository.HomeworkType.GetByName(template.TeacherId, req.Type); err != nil {
+		return errType
+	}
+	template.Name = req.Name
+	template.Description = req.Description
+	template.MaxPoints = maxPoints
+	template.Type = req.Type
+	return nil
+}
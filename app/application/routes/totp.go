@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var TotpHandler = &totpHandler{}
+
+type totpHandler struct{}
+
+// Login is the second sign in step: it checks the code of the teacher whose password was right and starts the session.
+func (h *totpHandler) Login(c *fiber.Ctx) error {
+	req := forms.TotpLoginRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("loginTotp", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	req.Challenge = c.Cookies(initializers.Cfg.TotpCookieKey)
+	actor, err := account.CompleteLogin(req, c.IP())
+	if errors.Is(err, account.ErrChallenge) {
+		c.ClearCookie(initializers.Cfg.TotpCookieKey)
+		return c.Status(fiber.StatusUnauthorized).Render("login", fiber.Map{
+			"error": failureMessage(err),
+		})
+	}
+	if err != nil {
+		return renderFailure(c, "loginTotp", err)
+	}
+	c.ClearCookie(initializers.Cfg.TotpCookieKey)
+	if err := startSession(c, actor); err != nil {
+		logrus.WithError(err)
+		return c.Render("login", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Redirect("/profile")
+}
+
+// Create shows a new secret to add to the authenticator app and asks for a code to confirm it.
+func (h *totpHandler) Create(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	setup, err := account.StartTotp(actor)
+	if err != nil {
+		return renderFailure(c, "totp", err)
+	}
+	return c.Render("totp", fiber.Map{
+		"secret": setup.Secret,
+		"uri":    setup.Uri,
+	})
+}
+
+// Confirm turns two-factor authentication on and shows the recovery codes.
+func (h *totpHandler) Confirm(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.TotpCodeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("totp", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	codes, err := account.EnableTotp(actor, req)
+	if err != nil {
+		if failure.KindOf(err) != failure.Invalid {
+			return renderFailure(c, "totp", err)
+		}
+		// the secret stays the same, so the teacher can try the next code
+		logrus.WithError(err)
+		setup := account.PendingTotp(actor)
+		return c.Status(utilities.StatusOf(err)).Render("totp", fiber.Map{
+			"error":  failureMessage(err),
+			"secret": setup.Secret,
+			"uri":    setup.Uri,
+		})
+	}
+	return c.Render("totp", fiber.Map{
+		"recoveryCodes": codes,
+	})
+}
+
+// CreateRecoveryCodes replaces the recovery codes and shows the new ones.
+func (h *totpHandler) CreateRecoveryCodes(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.TotpCodeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("totp", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	codes, err := account.NewRecoveryCodes(actor, req)
+	if err != nil {
+		return renderFailure(c, "totp", err)
+	}
+	return c.Render("totp", fiber.Map{
+		"recoveryCodes": codes,
+	})
+}
+
+// Delete turns two-factor authentication off.
+func (h *totpHandler) Delete(c *fiber.Ctx) error {
+	actor, err := managing(c, currentAccount)
+	if err != nil {
+		return renderError(c, err)
+	}
+	req := forms.TotpCodeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		logrus.WithError(err)
+		return c.Render("profileTeacher", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	if err := account.DisableTotp(actor, req); err != nil {
+		return renderFailure(c, "profileTeacher", err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// askForCode remembers the user who got past the password or the identity provider and asks for the code of their authenticator app.
+func askForCode(c *fiber.Ctx, actor *account.Actor) error {
+	challenge, expiresAt, err := account.Challenge(actor)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("login", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     initializers.Cfg.TotpCookieKey,
+		Value:    challenge,
+		Expires:  expiresAt,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+	return c.Render("loginTotp", fiber.Map{})
+}
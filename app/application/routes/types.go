@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"strconv"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/grading"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var TypeHandler = &typesHandler{}
+
+type typesHandler struct{}
+
+func (h *typesHandler) GetList(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	types, err := repository.HomeworkType.GetAvailable(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("types", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.Render("types", fiber.Map{
+		"types": *types,
+	})
+}
+
+func (h *typesHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	homeworkType := &models.HomeworkType{TeacherId: &actor.Teacher.Id}
+	if err := h.fill(c, homeworkType); err != nil {
+		logrus.WithError(err)
+		return c.Render("types", fiber.Map{
+			"error": err,
+		})
+	}
+	err = repository.HomeworkType.Create(homeworkType)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Status(fiber.StatusConflict).Render("types", fiber.Map{
+			"error": errTypeConflict,
+		})
+	}
+	return c.Redirect("/types")
+}
+
+func (h *typesHandler) Update(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	homeworkType, err := h.homeworkType(actor, c.Params("id"), policy.Update)
+	if err != nil {
+		return renderError(c, err)
+	}
+	oldName := homeworkType.Name
+	if err := h.fill(c, homeworkType); err != nil {
+		logrus.WithError(err)
+		return c.Render("types", fiber.Map{
+			"error": err,
+		})
+	}
+	err = repository.HomeworkType.Update(homeworkType, oldName)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Status(fiber.StatusConflict).Render("types", fiber.Map{
+			"error": errTypeConflict,
+		})
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *typesHandler) Delete(c *fiber.Ctx) error {
+	actor, err := currentTeacher(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	homeworkType, err := h.homeworkType(actor, c.Params("id"), policy.Delete)
+	if err != nil {
+		return renderError(c, err)
+	}
+	err = repository.HomeworkType.Delete(homeworkType)
+	if err != nil {
+		logrus.WithError(err)
+		return c.Render("types", fiber.Map{
+			"error": errSomethingWrong,
+		})
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *typesHandler) homeworkType(actor *account.Actor, param string, action policy.Action) (*models.HomeworkType, error) {
+	typeId, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		return nil, errNotFound
+	}
+	homeworkType, err := repository.HomeworkType.GetById(uint(typeId))
+	if err != nil {
+		return nil, errNotFound
+	}
+	if err := policy.HomeworkType(actor, action, homeworkType); err != nil {
+		return nil, err
+	}
+	return homeworkType, nil
+}
+
+func (h *typesHandler) fill(c *fiber.Ctx, homeworkType *models.HomeworkType) error {
+	req := forms.HomeworkTypeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return errSomethingWrong
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return errValidation
+	}
+	defaultMaxPoints, err := grading.Parse(req.DefaultMaxPoints)
+	if err != nil {
+		return err
+	}
+	homeworkType.Name = req.Name
+	homeworkType.Color = req.Color
+	homeworkType.DefaultMaxPoints = defaultMaxPoints
+	return nil
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+)
+
+var VerificationHandler = &verificationHandler{}
+
+type verificationHandler struct{}
+
+// Verify confirms the email the link was mailed to and opens the profile.
+func (h *verificationHandler) Verify(c *fiber.Ctx) error {
+	if _, err := account.Verify(c.Params("token")); err != nil {
+		return renderFailure(c, "login", err)
+	}
+	return c.Redirect("/profile")
+}
+
+// Create mails the link confirming the email of the signed in account again.
+func (h *verificationHandler) Create(c *fiber.Ctx) error {
+	actor, err := currentAccount(c)
+	if err != nil {
+		return renderError(c, err)
+	}
+	if err := account.ResendVerification(actor, func(token string) string {
+		return verifyLinkOf(c, token)
+	}); err != nil {
+		return renderFailure(c, profilePageOf(actor), err)
+	}
+	return c.Redirect("/profile")
+}
+
+// verifyLinkOf is the address that confirms the email the token was made for.
+func verifyLinkOf(c *fiber.Ctx, token string) string {
+	return c.BaseURL() + "/verify/" + token
+}
@@ -1,15 +1,27 @@
 package server
 
 import (
+	"github.com/MikhailR1337/task-sync-x/app/application/api"
 	"github.com/MikhailR1337/task-sync-x/app/application/middlewares"
+	"github.com/MikhailR1337/task-sync-x/app/application/openapi"
 	"github.com/MikhailR1337/task-sync-x/app/application/routes"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
 	"github.com/gofiber/fiber/v2"
 )
 
 func Init(app *fiber.App) {
 	middlewares.AddCommonMiddleware(app)
+	app.Get(openapi.Path, openapi.Handler(openapi.New("task-sync-x", "1.0.0", routes.Operations, api.Operations)))
+
+	v1 := app.Group(api.Prefix)
+	api.PublicRoutes(v1)
+	middlewares.AddApiTokenMiddleware(v1, account.ApiTokenPrefix, api.CheckApiToken)
+	middlewares.AddApiJwtMiddleware(v1, api.CheckSession, api.Unauthorized)
+	api.AuthorizedRoutes(v1)
+
 	routes.PublicRoutes(app)
 
-	middlewares.AddJwtMiddleware(app)
+	middlewares.AddApiTokenMiddleware(app, account.ApiTokenPrefix, routes.CheckApiToken)
+	middlewares.AddJwtMiddleware(app, routes.CheckSession, routes.RefreshSession)
 	routes.AuthorizedRoutes(app)
 }
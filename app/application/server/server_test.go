@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/api"
+	"github.com/MikhailR1337/task-sync-x/app/application/middlewares"
+	"github.com/MikhailR1337/task-sync-x/app/application/openapi"
+	"github.com/MikhailR1337/task-sync-x/app/application/routes"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/template/html"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newApp(t *testing.T) (*fiber.App, *openapi.Document) {
+	t.Helper()
+	initializers.Cfg.JwtSecretKey = "secret"
+	initializers.Cfg.JwtCookieKey = "jwt"
+	initializers.Cfg.ContextKeyUser = "user"
+	app := fiber.New()
+	Init(app)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, openapi.Path, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("GET %s: status %d, want %d", openapi.Path, resp.StatusCode, fiber.StatusOK)
+	}
+	document := &openapi.Document{}
+	if err := json.NewDecoder(resp.Body).Decode(document); err != nil {
+		t.Fatal(err)
+	}
+	return app, document
+}
+
+func TestSpecDescribesEveryRoute(t *testing.T) {
+	app, document := newApp(t)
+	for _, route := range document.Missing(app.GetRoutes(true)) {
+		t.Errorf("%s is registered but missing from the OpenAPI document", route)
+	}
+}
+
+func TestSpecHasNoUnknownRoutes(t *testing.T) {
+	app, document := newApp(t)
+	registered := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		registered[route.Method+" "+openapi.PathOf(route.Path)] = true
+	}
+	for path, item := range document.Paths {
+		for method := range item {
+			if !registered[strings.ToUpper(method)+" "+path] {
+				t.Errorf("%s %s is in the OpenAPI document but not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// TestApiTokensCannotManageTheAccount signs requests in the way a personal access token does and makes sure
+// the routes managing the account turn them away before the account is looked up.
+func TestApiTokensCannotManageTheAccount(t *testing.T) {
+	initializers.Cfg.ContextKeyUser = "user"
+	signIn := func(c *fiber.Ctx, token string) error {
+		utilities.SetJwtPayload(c, jwt.MapClaims{"sub": "1", "roles": "teacher", "tid": float64(1), "iat": float64(time.Now().Unix())})
+		return c.Next()
+	}
+	app := fiber.New(fiber.Config{Views: html.New("../../public/template", ".html"), ViewsLayout: "index"})
+	v1 := app.Group(api.Prefix)
+	middlewares.AddApiTokenMiddleware(v1, account.ApiTokenPrefix, signIn)
+	api.AuthorizedRoutes(v1)
+	middlewares.AddApiTokenMiddleware(app, account.ApiTokenPrefix, signIn)
+	routes.AuthorizedRoutes(app)
+
+	managing := []string{
+		"PATCH /profile",
+		"PATCH /profile/password",
+		"PATCH /profile/role",
+		"POST /profile/roles",
+		"DELETE /profile",
+		"POST /profile/totp",
+		"POST /profile/totp/confirm",
+		"POST /profile/totp/recovery-codes",
+		"DELETE /profile/totp",
+		"GET /sessions",
+		"DELETE /sessions",
+		"DELETE /sessions/1",
+		"POST /profile/tokens",
+		"DELETE /profile/tokens/1",
+	}
+	requests := []string{"GET " + api.Prefix + "/profile/tokens"}
+	for _, route := range managing {
+		method, path, _ := strings.Cut(route, " ")
+		requests = append(requests, route, method+" "+api.Prefix+path)
+	}
+	for _, request := range requests {
+		method, path, _ := strings.Cut(request, " ")
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+account.ApiTokenPrefix+"token")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusForbidden {
+			t.Errorf("%s with a personal access token: status %d, want %d", request, resp.StatusCode, fiber.StatusForbidden)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package utilities
 
 import (
 	"errors"
+	"math"
+	"strconv"
 
 	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
@@ -35,3 +38,43 @@ func GetJwtPayload(c *fiber.Ctx) (jwt.MapClaims, error) {
 
 	return jwtPayload, nil
 }
+
+// SetJwtPayload signs the request in with the claims, as if they came with a verified token.
+func SetJwtPayload(c *fiber.Ctx, claims jwt.MapClaims) {
+	c.Locals(initializers.Cfg.ContextKeyUser, &jwt.Token{Claims: claims, Valid: true})
+}
+
+// Writes reports whether the request may change things, which every method but GET, HEAD and OPTIONS may.
+func Writes(c *fiber.Ctx) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// SetRetryAfter tells the client when to try again if err says.
+func SetRetryAfter(c *fiber.Ctx, err error) {
+	if after := failure.RetryAfterOf(err); after > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(after.Seconds()))))
+	}
+}
+
+// StatusOf returns the HTTP status code matching the kind of err.
+func StatusOf(err error) int {
+	switch failure.KindOf(err) {
+	case failure.Unauthorized:
+		return fiber.StatusUnauthorized
+	case failure.Forbidden:
+		return fiber.StatusForbidden
+	case failure.NotFound:
+		return fiber.StatusNotFound
+	case failure.Conflict:
+		return fiber.StatusConflict
+	case failure.Invalid:
+		return fiber.StatusUnprocessableEntity
+	case failure.TooManyRequests:
+		return fiber.StatusTooManyRequests
+	}
+	return fiber.StatusInternalServerError
+}
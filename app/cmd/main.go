@@ -1,10 +1,14 @@
 package main
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/MikhailR1337/task-sync-x/app/application/server"
 	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/lockout"
+	"github.com/MikhailR1337/task-sync-x/app/services/oidc"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/template/html"
 	"github.com/sirupsen/logrus"
@@ -13,10 +17,33 @@ import (
 func main() {
 	initializers.InitConfig()
 	initializers.InitValidator()
+	initializers.InitStorage()
 	err := initializers.InitDb()
 	if err != nil {
 		logrus.Fatal(err)
 	}
+	// failed sign ins are counted in memory unless every server should see them
+	if initializers.Cfg.LockoutStore == "postgres" {
+		account.CountLoginsIn(lockout.NewPostgresStore())
+	}
+	// users sign in at the school's identity provider too once it is configured
+	if initializers.Cfg.OidcIssuer != "" {
+		provider, err := oidc.Discover(oidc.Config{
+			Issuer:       initializers.Cfg.OidcIssuer,
+			ClientId:     initializers.Cfg.OidcClientId,
+			ClientSecret: initializers.Cfg.OidcClientSecret,
+			Scopes:       []string{"email", "profile"},
+			RoleClaim:    initializers.Cfg.OidcRoleClaim,
+		}, &http.Client{Timeout: 10 * time.Second})
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		account.SignInWith(provider)
+	}
+	err = account.EnsureAdmin(initializers.Cfg.AdminEmail, initializers.Cfg.AdminPassword)
+	if err != nil {
+		logrus.Fatal(err)
+	}
 	engine := html.New("public/template", ".html")
 	app := fiber.New(fiber.Config{
 		Views:        engine,
@@ -1,18 +1,201 @@
 package migrate
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
 	"gorm.io/gorm"
 )
 
 func Migrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
+		&models.User{},
 		&models.Teacher{},
 		&models.Student{},
 		&models.Homework{},
+		&models.Group{},
+		&models.HomeworkTemplate{},
+		&models.HomeworkType{},
+		&models.Rubric{},
+		&models.RubricCriterion{},
+		&models.CriterionScore{},
+		&models.Attempt{},
+		&models.Submission{},
+		&models.SubmissionFile{},
+		&models.Comment{},
+		&models.JoinRequest{},
+		&models.Invite{},
+		&models.Enrollment{},
+		&models.Admin{},
+		&models.AuditEntry{},
+		&models.PasswordReset{},
+		&models.Session{},
+		&models.RecoveryCode{},
+		&models.Setting{},
+		&models.LoginAttempt{},
+		&models.Identity{},
+		&models.ApiToken{},
 	)
 	if err != nil {
 		return err
 	}
+	if err := enrollStudents(db); err != nil {
+		return err
+	}
+	if err := mergeAccounts(db); err != nil {
+		return err
+	}
+	if err := purgeHomeworkTypes(db); err != nil {
+		return err
+	}
+	return seedHomeworkTypes(db)
+}
+
+// enrollStudents turns the teacher students had before they could study with several teachers into enrollments.
+func enrollStudents(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Student{}, "teacher_id") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`insert into enrollments (student_id, teacher_id, status, starts_at, created_at, updated_at)
+			select students.id, students.teacher_id, ?, students.updated_at, now(), now()
+			from students join teachers on teachers.id = students.teacher_id and teachers.deleted_at is null
+			where students.deleted_at is null
+			on conflict (student_id, teacher_id) do nothing`, models.EnrollmentActive).Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&models.Student{}, "teacher_id")
+	})
+}
+
+// account is a teacher or student row as it was before users signed in once for both roles.
+type account struct {
+	Email             string
+	Name              string
+	Password          string
+	VerifiedAt        *time.Time
+	PasswordChangedAt *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// changedAt is when the password of the account was set last.
+func (a *account) changedAt() time.Time {
+	if a.PasswordChangedAt != nil {
+		return *a.PasswordChangedAt
+	}
+	return a.UpdatedAt
+}
+
+// preferred reports whether the user keeps the password of a rather than b. A confirmed email wins, since anyone
+// could register an unconfirmed one, and then the password changed last.
+func (a *account) preferred(b *account) bool {
+	if (a.VerifiedAt != nil) != (b.VerifiedAt != nil) {
+		return a.VerifiedAt != nil
+	}
+	return a.changedAt().After(b.changedAt())
+}
+
+// chooseAccounts keeps the account every user is made from, one for each email in the order they come.
+func chooseAccounts(accounts []account) []account {
+	chosen := []account{}
+	byEmail := map[string]int{}
+	for _, a := range accounts {
+		i, ok := byEmail[a.Email]
+		if !ok {
+			byEmail[a.Email] = len(chosen)
+			chosen = append(chosen, a)
+			continue
+		}
+		if a.preferred(&chosen[i]) {
+			chosen[i] = a
+		}
+	}
+	return chosen
+}
+
+// mergeAccounts turns the teachers and students, who signed in with their own email and password, into users.
+// Someone who both taught and studied becomes one user with the password of the confirmed account, or the one
+// they changed last; homework stays with the teacher and student rows, which become the user's roles.
+// Sessions of the old accounts end.
+func mergeAccounts(db *gorm.DB) error {
+	accounts := []string{}
+	for _, table := range []string{"teachers", "students"} {
+		if db.Migrator().HasColumn(table, "password") {
+			accounts = append(accounts, table)
+		}
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		selects := make([]string, 0, len(accounts))
+		for _, table := range accounts {
+			// accounts made before emails were confirmed are trusted, the check is for the ones registering since
+			verifiedAt := "created_at"
+			if tx.Migrator().HasColumn(table, "verified_at") {
+				verifiedAt = "verified_at"
+			}
+			passwordChangedAt := "null::timestamptz"
+			if tx.Migrator().HasColumn(table, "password_changed_at") {
+				passwordChangedAt = "password_changed_at"
+			}
+			selects = append(selects, fmt.Sprintf(`select email, name, password, %s as verified_at,
+				%s as password_changed_at, created_at, updated_at from %s where deleted_at is null`,
+				verifiedAt, passwordChangedAt, table))
+		}
+		rows := []account{}
+		if err := tx.Raw(strings.Join(selects, " union all ") + " order by email").Scan(&rows).Error; err != nil {
+			return err
+		}
+		for _, a := range chooseAccounts(rows) {
+			err := tx.Exec(`insert into users (email, name, password, verified_at, password_changed_at, created_at, updated_at)
+				values (?, ?, ?, ?, ?, ?, now()) on conflict (email) do nothing`,
+				a.Email, a.Name, a.Password, a.VerifiedAt, a.PasswordChangedAt, a.CreatedAt).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, table := range accounts {
+			err := tx.Exec(fmt.Sprintf(`update %[1]s set user_id = users.id from users
+				where users.email = %[1]s.email and %[1]s.deleted_at is null`, table)).Error
+			if err != nil {
+				return err
+			}
+			for _, column := range []string{"password", "verified_at", "password_changed_at"} {
+				if !tx.Migrator().HasColumn(table, column) {
+					continue
+				}
+				if err := tx.Migrator().DropColumn(table, column); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Model(&models.Session{}).
+			Where("role <> ? and revoked_at is null", "admin").
+			Update("revoked_at", gorm.Expr("now()")).Error
+	})
+}
+
+// purgeHomeworkTypes removes the types deleted before deleting a type removed it for good, as they keep their names taken.
+func purgeHomeworkTypes(db *gorm.DB) error {
+	return db.Unscoped().Where("deleted_at is not null").Delete(&models.HomeworkType{}).Error
+}
+
+// seedHomeworkTypes creates the global types homework had before types became configurable.
+func seedHomeworkTypes(db *gorm.DB) error {
+	defaults := []models.HomeworkType{
+		{Name: "listening", Color: "#4a90d9", DefaultMaxPoints: 40},
+		{Name: "reading", Color: "#50b36b", DefaultMaxPoints: 40},
+	}
+	for _, homeworkType := range defaults {
+		err := db.Where("teacher_id is null and name = ?", homeworkType.Name).FirstOrCreate(&homeworkType).Error
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func at(days int) *time.Time {
+	t := day.AddDate(0, 0, days)
+	return &t
+}
+
+func TestChooseAccounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts []account
+		want     []string
+	}{
+		{
+			name: "one account for each email",
+			accounts: []account{
+				{Email: "teacher@example.com", Password: "teacher", VerifiedAt: at(0), UpdatedAt: *at(0)},
+				{Email: "student@example.com", Password: "student", UpdatedAt: *at(1)},
+			},
+			want: []string{"teacher", "student"},
+		},
+		{
+			name: "a confirmed account wins over a newer unconfirmed one",
+			accounts: []account{
+				{Email: "teacher@example.com", Password: "teacher", VerifiedAt: at(0), UpdatedAt: *at(0)},
+				{Email: "teacher@example.com", Password: "squatter", PasswordChangedAt: at(5), UpdatedAt: *at(5)},
+			},
+			want: []string{"teacher"},
+		},
+		{
+			name: "a confirmed account wins over an older unconfirmed one",
+			accounts: []account{
+				{Email: "teacher@example.com", Password: "squatter", UpdatedAt: *at(0)},
+				{Email: "teacher@example.com", Password: "teacher", VerifiedAt: at(1), UpdatedAt: *at(1)},
+			},
+			want: []string{"teacher"},
+		},
+		{
+			name: "the password changed last wins between confirmed accounts",
+			accounts: []account{
+				{Email: "tutor@example.com", Password: "old", VerifiedAt: at(0), PasswordChangedAt: at(1), UpdatedAt: *at(9)},
+				{Email: "tutor@example.com", Password: "new", VerifiedAt: at(0), PasswordChangedAt: at(2), UpdatedAt: *at(2)},
+			},
+			want: []string{"new"},
+		},
+		{
+			name: "the update time stands in for a password never changed",
+			accounts: []account{
+				{Email: "tutor@example.com", Password: "new", UpdatedAt: *at(3)},
+				{Email: "tutor@example.com", Password: "old", PasswordChangedAt: at(2), UpdatedAt: *at(4)},
+			},
+			want: []string{"new"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen := chooseAccounts(tt.accounts)
+			if len(chosen) != len(tt.want) {
+				t.Fatalf("got %d users, want %d", len(chosen), len(tt.want))
+			}
+			for i, a := range chosen {
+				if a.Password != tt.want[i] {
+					t.Errorf("user %d keeps password %q, want %q", i, a.Password, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Admin manages the accounts of teachers and students. Admins are created from the configuration, never by registration.
+type Admin struct {
+	gorm.Model
+	Id       uint   `gorm:"primaryKey"`
+	Email    string `gorm:"uniqueIndex;not null"`
+	Name     string `gorm:"not null"`
+	Password string `gorm:"not null"`
+	// PasswordChangedAt signs out the sessions started before it.
+	PasswordChangedAt *time.Time `gorm:"default:null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// AuditEntry records what an admin did to whom.
+type AuditEntry struct {
+	Id        uint   `gorm:"primaryKey"`
+	AdminId   uint   `gorm:"not null;index"`
+	Admin     Admin  `gorm:"constraint:OnUpdate:CASCADE;"`
+	Action    string `gorm:"not null"`
+	Subject   string `gorm:"not null"`
+	SubjectId uint   `gorm:"not null"`
+	Details   string
+	CreatedAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// ApiToken is a personal access token the user made for a script or an integration. It acts in Role like a session
+// of the user does, for as long as it has not expired or been revoked; only its hash is kept.
+// Scope is read, which lets it look, or write, which lets it change things too.
+type ApiToken struct {
+	Id         uint      `gorm:"primaryKey"`
+	UserId     uint      `gorm:"not null;index"`
+	Role       string    `gorm:"not null"`
+	Name       string    `gorm:"not null"`
+	Hash       string    `gorm:"not null;uniqueIndex"`
+	Scope      string    `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// Active reports whether the token can still be used at the moment.
+func (t *ApiToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attempt is one round of work on homework: a submission and the teacher's review of it.
+type Attempt struct {
+	gorm.Model
+	Id           uint     `gorm:"primaryKey"`
+	HomeworkId   uint     `gorm:"not null;uniqueIndex:idx_attempt_number"`
+	Number       uint     `gorm:"not null;uniqueIndex:idx_attempt_number"`
+	Status       string   `gorm:"not null"`
+	Points       *float64 `gorm:"type:numeric(7,2)"`
+	Feedback     string
+	ReturnReason string
+	Submission   *Submission      `gorm:"foreignKey:AttemptId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Scores       []CriterionScore `gorm:"foreignKey:AttemptId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	SubmittedAt  time.Time
+	ReviewedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Comment struct {
+	gorm.Model
+	Id         uint   `gorm:"primaryKey"`
+	HomeworkId uint   `gorm:"not null;index"`
+	AuthorId   uint   `gorm:"not null"`
+	AuthorRole string `gorm:"not null"`
+	Body       string `gorm:"not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
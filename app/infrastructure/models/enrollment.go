@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of an enrollment.
+const (
+	EnrollmentActive   = "active"
+	EnrollmentFinished = "finished"
+)
+
+// Enrollment is a student studying with a teacher. A student may study with several teachers.
+type Enrollment struct {
+	gorm.Model
+	Id        uint       `gorm:"primaryKey"`
+	StudentId uint       `gorm:"not null;uniqueIndex:idx_enrollment"`
+	TeacherId uint       `gorm:"not null;uniqueIndex:idx_enrollment;index"`
+	Status    string     `gorm:"not null"`
+	StartsAt  time.Time  `gorm:"not null"`
+	EndsAt    *time.Time `gorm:"default:null"`
+	Student   Student    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Teacher   Teacher    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (e *Enrollment) Active() bool {
+	return e.Status == EnrollmentActive
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Group struct {
+	gorm.Model
+	Id        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"not null"`
+	TeacherId uint      `gorm:"not null;index"`
+	Students  []Student `gorm:"many2many:group_students;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -11,12 +11,19 @@ type Homework struct {
 	Id            uint   `gorm:"primaryKey"`
 	Name          string `gorm:"not null"`
 	Description   string
-	CurrentPoints uint8  `gorm:"not null;default:0"`
-	MaxPoints     uint8  `gorm:"not null;default:40"`
-	Type          string `gorm:"not null"`
-	Status        string `gorm:"not null"`
-	TeacherId     uint   `gorm:"not null"`
-	StudentId     uint   `gorm:"not null"`
+	CurrentPoints float64 `gorm:"type:numeric(7,2);not null;default:0"`
+	MaxPoints     float64 `gorm:"type:numeric(7,2);not null;default:40"`
+	Type          string  `gorm:"not null"`
+	Status        string  `gorm:"not null"`
+	TeacherId     uint    `gorm:"not null"`
+	StudentId     uint    `gorm:"not null"`
+	RubricId      *uint
+	DueAt         *time.Time
+	SubmittedAt   *time.Time
+	LatePenalty   uint8        `gorm:"not null;default:0"`
+	Submissions   []Submission `gorm:"foreignKey:HomeworkId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Attempts      []Attempt    `gorm:"foreignKey:HomeworkId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Comments      []Comment    `gorm:"foreignKey:HomeworkId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
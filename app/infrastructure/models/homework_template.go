@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type HomeworkTemplate struct {
+	gorm.Model
+	Id          uint   `gorm:"primaryKey"`
+	TeacherId   uint   `gorm:"not null;index"`
+	Name        string `gorm:"not null"`
+	Description string
+	MaxPoints   float64 `gorm:"type:numeric(7,2);not null;default:40"`
+	Type        string  `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTypeColor is the color of the types created without one, like the ones templates bring along when imported.
+const DefaultTypeColor = "#808080"
+
+// HomeworkType is a category of homework. Types without a teacher are available to everyone.
+type HomeworkType struct {
+	gorm.Model
+	Id               uint    `gorm:"primaryKey"`
+	TeacherId        *uint   `gorm:"uniqueIndex:idx_homework_type_name"`
+	Name             string  `gorm:"not null;uniqueIndex:idx_homework_type_name"`
+	Color            string  `gorm:"not null;default:'#808080'"`
+	DefaultMaxPoints float64 `gorm:"type:numeric(7,2);not null;default:40"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// Identity links the user to the account they have at an OpenID Connect provider, which the subject names for good.
+type Identity struct {
+	Id        uint   `gorm:"primaryKey"`
+	UserId    uint   `gorm:"not null;index"`
+	Issuer    string `gorm:"not null;uniqueIndex:idx_identity_subject"`
+	Subject   string `gorm:"not null;uniqueIndex:idx_identity_subject"`
+	CreatedAt time.Time
+}
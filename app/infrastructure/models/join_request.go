@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JoinRequest is a student asking a teacher to take them into the class.
+type JoinRequest struct {
+	gorm.Model
+	Id        uint    `gorm:"primaryKey"`
+	StudentId uint    `gorm:"not null;index"`
+	TeacherId uint    `gorm:"not null;index"`
+	Status    string  `gorm:"not null"`
+	Student   Student `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Teacher   Teacher `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Invite is the code a teacher shares so that students join without waiting for approval.
+type Invite struct {
+	gorm.Model
+	Id        uint   `gorm:"primaryKey"`
+	TeacherId uint   `gorm:"not null;uniqueIndex"`
+	Code      string `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt counts the failed sign ins of an account or an IP address, named by Key.
+type LoginAttempt struct {
+	Key          string `gorm:"primaryKey"`
+	Failures     int    `gorm:"not null"`
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordReset is a one-time token mailed to an account that forgot its password. Only the hash of the token is kept.
+// Role tells whether the password is of the admin or of the user with the email.
+type PasswordReset struct {
+	Id        uint      `gorm:"primaryKey"`
+	Email     string    `gorm:"not null;index"`
+	Role      string    `gorm:"not null"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// RecoveryCode stands in for an authenticator code once, for teachers who lost their app. Only the hash of the code is kept.
+type RecoveryCode struct {
+	Id        uint   `gorm:"primaryKey"`
+	TeacherId uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Rubric struct {
+	gorm.Model
+	Id        uint              `gorm:"primaryKey"`
+	TeacherId uint              `gorm:"not null;index"`
+	Name      string            `gorm:"not null"`
+	Criteria  []RubricCriterion `gorm:"foreignKey:RubricId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (r *Rubric) MaxPoints() float64 {
+	var total float64
+	for _, criterion := range r.Criteria {
+		total += criterion.MaxPoints
+	}
+	return total
+}
+
+type RubricCriterion struct {
+	gorm.Model
+	Id        uint    `gorm:"primaryKey"`
+	RubricId  uint    `gorm:"not null;index"`
+	Name      string  `gorm:"not null"`
+	MaxPoints float64 `gorm:"type:numeric(7,2);not null"`
+	Position  int     `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CriterionScore keeps the points an attempt got for a criterion. Name and MaxPoints
+// are copied so that the result survives changes to the rubric.
+type CriterionScore struct {
+	gorm.Model
+	Id          uint    `gorm:"primaryKey"`
+	AttemptId   uint    `gorm:"not null;index"`
+	CriterionId uint    `gorm:"not null"`
+	Name        string  `gorm:"not null"`
+	Points      float64 `gorm:"type:numeric(7,2);not null"`
+	MaxPoints   float64 `gorm:"type:numeric(7,2);not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
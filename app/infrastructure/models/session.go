@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is a signed in device. Access tokens name the session, so revoking it signs the device out;
+// the refresh token that renews them rotates on every use and only its hash is kept.
+// AccountId is the user or, for the admin role, the admin; Role is the role they act in on the device.
+type Session struct {
+	Id           uint   `gorm:"primaryKey"`
+	AccountId    uint   `gorm:"not null;index:idx_session_account"`
+	Role         string `gorm:"not null;index:idx_session_account"`
+	RefreshHash  string `gorm:"not null;uniqueIndex"`
+	PreviousHash string `gorm:"index"`
+	UserAgent    string
+	Ip           string
+	ExpiresAt    time.Time `gorm:"not null"`
+	LastUsedAt   time.Time
+	RevokedAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Active reports whether the session can still be used at the moment.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,12 @@
+package models
+
+import (
+	"time"
+)
+
+// Setting is an option admins change while the app runs.
+type Setting struct {
+	Name      string `gorm:"primaryKey"`
+	Value     string `gorm:"not null"`
+	UpdatedAt time.Time
+}
@@ -6,14 +6,27 @@ import (
 	"gorm.io/gorm"
 )
 
+// Student is the studying role of a user. Email and Name repeat the user's, so lists of students need no join.
 type Student struct {
 	gorm.Model
-	Id        uint       `gorm:"primaryKey"`
-	Email     string     `gorm:"uniqueIndex;not null"`
-	Name      string     `gorm:"not null"`
-	Password  string     `gorm:"not null"`
-	TeacherId uint       `gorm:"default:null"`
-	Homeworks []Homework `gorm:"foreignKey:StudentId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id            uint         `gorm:"primaryKey"`
+	UserId        uint         `gorm:"index"`
+	Email         string       `gorm:"uniqueIndex;not null"`
+	Name          string       `gorm:"not null"`
+	DeactivatedAt *time.Time   `gorm:"default:null"`
+	Enrollments   []Enrollment `gorm:"foreignKey:StudentId"`
+	Homeworks     []Homework   `gorm:"foreignKey:StudentId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// EnrollmentWith returns the student's enrollment with the teacher, nil when they never studied together.
+// Enrollments have to be loaded.
+func (s *Student) EnrollmentWith(teacherId uint) *Enrollment {
+	for i := range s.Enrollments {
+		if s.Enrollments[i].TeacherId == teacherId {
+			return &s.Enrollments[i]
+		}
+	}
+	return nil
 }
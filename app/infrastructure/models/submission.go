@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Submission struct {
+	gorm.Model
+	Id         uint `gorm:"primaryKey"`
+	HomeworkId uint `gorm:"not null;index"`
+	AttemptId  uint `gorm:"index"`
+	Answer     string
+	Files      []SubmissionFile `gorm:"foreignKey:SubmissionId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type SubmissionFile struct {
+	gorm.Model
+	Id           uint   `gorm:"primaryKey"`
+	SubmissionId uint   `gorm:"not null;index"`
+	Name         string `gorm:"not null"`
+	Key          string `gorm:"not null"`
+	Size         int64
+	ContentType  string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
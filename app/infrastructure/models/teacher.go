@@ -6,14 +6,21 @@ import (
 	"gorm.io/gorm"
 )
 
+// Teacher is the teaching role of a user. Email and Name repeat the user's, so lists of teachers need no join.
 type Teacher struct {
 	gorm.Model
-	Id        uint       `gorm:"primaryKey"`
-	Email     string     `gorm:"uniqueIndex;notnull"`
-	Name      string     `gorm:"not null"`
-	Password  string     `gorm:"not null"`
-	Students  []Student  `gorm:"foreignKey:TeacherId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Homeworks []Homework `gorm:"foreignKey:TeacherId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id            uint       `gorm:"primaryKey"`
+	UserId        uint       `gorm:"index"`
+	Email         string     `gorm:"uniqueIndex;notnull"`
+	Name          string     `gorm:"not null"`
+	DeactivatedAt *time.Time `gorm:"default:null"`
+	// TotpSecret is shared with the authenticator app; codes are asked for at sign in once TotpEnabledAt is set.
+	TotpSecret    string
+	TotpEnabledAt *time.Time `gorm:"default:null"`
+	// TotpLastStep is the period of the last accepted code, so a code cannot be used twice.
+	TotpLastStep int64
+	Homeworks    []Homework `gorm:"foreignKey:TeacherId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Groups       []Group    `gorm:"foreignKey:TeacherId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
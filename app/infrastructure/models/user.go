@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// User is a person who signs in. They teach, study or both: the Teacher and Student with their UserId are the roles they have.
+type User struct {
+	Id       uint   `gorm:"primaryKey"`
+	Email    string `gorm:"uniqueIndex;not null"`
+	Name     string `gorm:"not null"`
+	Password string `gorm:"not null"`
+	// VerifiedAt is when the user confirmed the email; notifications go to confirmed emails only.
+	VerifiedAt *time.Time `gorm:"default:null"`
+	// PasswordChangedAt signs out the sessions started before it.
+	PasswordChangedAt *time.Time `gorm:"default:null"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
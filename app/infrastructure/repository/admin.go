@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errAdminNotFound      = errors.New("admin is not found")
+	errAdminNotCreated    = errors.New("admin is not created")
+	errAdminNotUpdated    = errors.New("admin is not updated")
+	errAuditEntryNotFound = errors.New("audit entry is not found")
+	errAuditEntryNotSaved = errors.New("audit entry is not saved")
+)
+
+var (
+	Admin = &admin{&initializers.DB}
+	Audit = &audit{&initializers.DB}
+)
+
+type (
+	admin struct {
+		storage *initializers.PgDb
+	}
+	audit struct {
+		storage *initializers.PgDb
+	}
+)
+
+func (h *admin) GetById(id uint) (*models.Admin, error) {
+	admin := &models.Admin{}
+	result := h.storage.Where("id = ?", id).Take(admin)
+	if result.Error != nil {
+		return nil, errAdminNotFound
+	}
+	return admin, nil
+}
+
+func (h *admin) GetByEmail(email string) (*models.Admin, error) {
+	admin := &models.Admin{}
+	result := h.storage.Where("email = ?", email).Take(admin)
+	if result.Error != nil {
+		return nil, errAdminNotFound
+	}
+	return admin, nil
+}
+
+func (h *admin) Create(model *models.Admin) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errAdminNotCreated
+	}
+	return nil
+}
+
+func (h *admin) Update(model *models.Admin) error {
+	if err := h.storage.Save(model).Error; err != nil {
+		return errAdminNotUpdated
+	}
+	return nil
+}
+
+// GetLatest returns the latest entries with the admins who made them, newest first.
+func (h *audit) GetLatest(limit int) (*[]models.AuditEntry, error) {
+	entries := &[]models.AuditEntry{}
+	result := h.storage.Preload("Admin").Order("created_at desc, id desc").Limit(limit).Find(entries)
+	if result.Error != nil {
+		return nil, errAuditEntryNotFound
+	}
+	return entries, nil
+}
+
+func (h *audit) Create(model *models.AuditEntry) error {
+	if err := h.storage.Omit("Admin").Create(model).Error; err != nil {
+		return errAuditEntryNotSaved
+	}
+	return nil
+}
+
+// Save saves the changed models together with the entry that records the change, or none of them.
+func (h *audit) Save(model *models.AuditEntry, changed ...interface{}) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changed {
+			if err := tx.Omit(clause.Associations).Save(change).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Admin").Create(model).Error
+	})
+	if err != nil {
+		return errAuditEntryNotSaved
+	}
+	return nil
+}
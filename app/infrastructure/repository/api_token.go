@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errApiTokenNotFound   = errors.New("api token is not found")
+	errApiTokenNotCreated = errors.New("api token is not created")
+	errApiTokenNotUpdated = errors.New("api token is not updated")
+	errApiTokenNotRevoked = errors.New("api token is not revoked")
+)
+
+var ApiToken = &apiToken{&initializers.DB}
+
+type apiToken struct {
+	storage *initializers.PgDb
+}
+
+func (h *apiToken) GetById(id uint) (*models.ApiToken, error) {
+	token := &models.ApiToken{}
+	result := h.storage.Where("id = ?", id).Take(token)
+	if result.Error != nil {
+		return nil, errApiTokenNotFound
+	}
+	return token, nil
+}
+
+func (h *apiToken) GetByHash(hash string) (*models.ApiToken, error) {
+	token := &models.ApiToken{}
+	result := h.storage.Where("hash = ?", hash).Take(token)
+	if result.Error != nil {
+		return nil, errApiTokenNotFound
+	}
+	return token, nil
+}
+
+// GetActive returns the tokens of the user that still work, the latest made first.
+func (h *apiToken) GetActive(userId uint) (*[]models.ApiToken, error) {
+	tokens := &[]models.ApiToken{}
+	result := h.storage.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("created_at desc").
+		Find(tokens)
+	if result.Error != nil {
+		return nil, errApiTokenNotFound
+	}
+	return tokens, nil
+}
+
+func (h *apiToken) Create(model *models.ApiToken) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errApiTokenNotCreated
+	}
+	return nil
+}
+
+// Touch remembers when the token was last used.
+func (h *apiToken) Touch(model *models.ApiToken, now time.Time) error {
+	if err := h.storage.Model(model).Update("last_used_at", now).Error; err != nil {
+		return errApiTokenNotUpdated
+	}
+	model.LastUsedAt = &now
+	return nil
+}
+
+func (h *apiToken) Revoke(model *models.ApiToken) error {
+	now := time.Now()
+	if err := h.storage.Model(model).Update("revoked_at", now).Error; err != nil {
+		return errApiTokenNotRevoked
+	}
+	model.RevokedAt = &now
+	return nil
+}
+
+// RevokeAll revokes every token of the user, whatever role it acts in.
+func (h *apiToken) RevokeAll(userId uint) error {
+	result := h.storage.Model(&models.ApiToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return errApiTokenNotRevoked
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errCommentNotFound   = errors.New("comment is not found")
+	errCommentNotCreated = errors.New("comment is not created")
+)
+
+var Comment = &comment{&initializers.DB}
+
+type comment struct {
+	storage *initializers.PgDb
+}
+
+func (h *comment) GetByHomeworkId(id uint) (*[]models.Comment, error) {
+	comments := &[]models.Comment{}
+	result := h.storage.Where("homework_id = ?", id).Order("created_at").Find(comments)
+	if result.Error != nil {
+		return nil, errCommentNotFound
+	}
+	return comments, nil
+}
+
+func (h *comment) Create(model *models.Comment) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errCommentNotCreated
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errEnrollmentNotFound = errors.New("enrollment is not found")
+	errEnrollmentNotSaved = errors.New("enrollment is not saved")
+)
+
+var Enrollment = &enrollment{&initializers.DB}
+
+// restart starts the old enrollment of the student with the teacher again instead of making a second one.
+var restart = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "student_id"}, {Name: "teacher_id"}},
+	DoUpdates: clause.AssignmentColumns([]string{"status", "starts_at", "ends_at", "updated_at", "deleted_at"}),
+}
+
+type enrollment struct {
+	storage *initializers.PgDb
+}
+
+func (h *enrollment) GetById(id uint) (*models.Enrollment, error) {
+	enrollment := &models.Enrollment{}
+	result := h.storage.Preload("Student").Preload("Teacher").Where("id = ?", id).Take(enrollment)
+	if result.Error != nil {
+		return nil, errEnrollmentNotFound
+	}
+	return enrollment, nil
+}
+
+// GetByStudentId returns every enrollment of the student with its teacher, active ones first.
+func (h *enrollment) GetByStudentId(id uint) (*[]models.Enrollment, error) {
+	enrollments := &[]models.Enrollment{}
+	result := h.storage.Preload("Teacher").Where("student_id = ?", id).Order("status, starts_at").Find(enrollments)
+	if result.Error != nil {
+		return nil, errEnrollmentNotFound
+	}
+	return enrollments, nil
+}
+
+// GetByTeacherId returns every enrollment of the teacher with its student, active ones first.
+func (h *enrollment) GetByTeacherId(id uint) (*[]models.Enrollment, error) {
+	enrollments := &[]models.Enrollment{}
+	result := h.storage.Preload("Student").Where("teacher_id = ?", id).Order("status, starts_at").Find(enrollments)
+	if result.Error != nil {
+		return nil, errEnrollmentNotFound
+	}
+	return enrollments, nil
+}
+
+// Save stores the enrollment. Enrolling with a teacher the student studied with before starts the old enrollment again.
+func (h *enrollment) Save(model *models.Enrollment) error {
+	result := h.storage.Omit(clause.Associations).Clauses(restart).Create(model)
+	if result.Error != nil {
+		return errEnrollmentNotSaved
+	}
+	return nil
+}
+
+// Reassign saves the finished enrollment and the one with the new teacher together with the audit entry recording it.
+func (h *enrollment) Reassign(finished *models.Enrollment, next *models.Enrollment, entry *models.AuditEntry) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(finished).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Clauses(restart).Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Admin").Create(entry).Error
+	})
+	if err != nil {
+		return errEnrollmentNotSaved
+	}
+	return nil
+}
+
+func (h *enrollment) Update(model *models.Enrollment) error {
+	if err := h.storage.Omit(clause.Associations).Save(model).Error; err != nil {
+		return errEnrollmentNotSaved
+	}
+	return nil
+}
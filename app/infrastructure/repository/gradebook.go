@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var errGradebookNotFound = errors.New("gradebook is not found")
+
+var Gradebook = &gradebook{&initializers.DB}
+
+type gradebook struct {
+	storage *initializers.PgDb
+}
+
+// GradebookAssignment is a column of the gradebook: the homework with the name that each student got as the Number-th one.
+type GradebookAssignment struct {
+	Name   string
+	Number int
+}
+
+// Title is the heading of the column, numbering the homework given again under the same name.
+func (a GradebookAssignment) Title() string {
+	if a.Number == 1 {
+		return a.Name
+	}
+	return fmt.Sprintf("%s (%d)", a.Name, a.Number)
+}
+
+type GradebookCell struct {
+	HomeworkId    uint
+	StudentId     uint
+	StudentName   string
+	Name          string
+	Number        int
+	Status        string
+	CurrentPoints float64
+	MaxPoints     float64
+}
+
+type StudentProgress struct {
+	StudentId      uint
+	StudentName    string
+	Total          int
+	Completed      int
+	WithDueDate    int
+	OnTime         int
+	AveragePercent *float64
+}
+
+// CompletionRate is the percent of homework the student has finished.
+func (p StudentProgress) CompletionRate() float64 {
+	return rate(p.Completed, p.Total)
+}
+
+// OnTimeRate is the percent of homework with a due date that was submitted in time.
+func (p StudentProgress) OnTimeRate() float64 {
+	return rate(p.OnTime, p.WithDueDate)
+}
+
+type TrendPoint struct {
+	Week           time.Time
+	Checked        int
+	AveragePercent float64
+}
+
+func rate(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 10
+}
+
+// numbered is how many homeworks with the same name the student got before, counting this one.
+const numbered = "row_number() over (partition by homeworks.student_id, homeworks.name order by homeworks.created_at, homeworks.id)"
+
+// GetAssignments returns the columns of the teacher's gradebook in the order they were first given.
+// A student who got homework with the same name twice has it in two columns, so no score hides another.
+func (h *gradebook) GetAssignments(teacherId uint) ([]GradebookAssignment, error) {
+	assignments := []GradebookAssignment{}
+	homeworks := h.storage.Model(&models.Homework{}).
+		Select("homeworks.name, homeworks.created_at, "+numbered+" as number").
+		Where("homeworks.teacher_id = ?", teacherId)
+	result := h.storage.Table("(?) as homeworks", homeworks).
+		Select("name, number").
+		Group("name, number").
+		Order("min(created_at), number").
+		Scan(&assignments)
+	if result.Error != nil {
+		return nil, errGradebookNotFound
+	}
+	return assignments, nil
+}
+
+func (h *gradebook) GetCells(teacherId uint) (*[]GradebookCell, error) {
+	cells := &[]GradebookCell{}
+	result := h.storage.Model(&models.Homework{}).
+		Select("homeworks.id as homework_id, homeworks.student_id, students.name as student_name, homeworks.name, "+numbered+" as number, homeworks.status, homeworks.current_points, homeworks.max_points").
+		Joins("join students on students.id = homeworks.student_id and students.deleted_at is null").
+		Where("homeworks.teacher_id = ?", teacherId).
+		Order("students.name, homeworks.created_at").
+		Scan(cells)
+	if result.Error != nil {
+		return nil, errGradebookNotFound
+	}
+	return cells, nil
+}
+
+func (h *gradebook) GetProgress(teacherId uint) (*[]StudentProgress, error) {
+	progress := &[]StudentProgress{}
+	result := h.storage.Model(&models.Homework{}).
+		Select(`homeworks.student_id, students.name as student_name,
+			count(*) as total,
+			count(*) filter (where homeworks.status in ('finished', 'checked')) as completed,
+			count(*) filter (where homeworks.due_at is not null and (homeworks.submitted_at is not null or homeworks.due_at < now())) as with_due_date,
+			count(*) filter (where homeworks.submitted_at <= homeworks.due_at) as on_time,
+			round(avg(homeworks.current_points / nullif(homeworks.max_points, 0) * 100) filter (where homeworks.status = 'checked'), 1) as average_percent`).
+		Joins("join students on students.id = homeworks.student_id and students.deleted_at is null").
+		Where("homeworks.teacher_id = ?", teacherId).
+		Group("homeworks.student_id, students.name").
+		Order("students.name").
+		Scan(progress)
+	if result.Error != nil {
+		return nil, errGradebookNotFound
+	}
+	return progress, nil
+}
+
+// GetTrend returns the weekly average result of checked homework. A zero studentId means all students.
+func (h *gradebook) GetTrend(teacherId uint, studentId uint) (*[]TrendPoint, error) {
+	trend := &[]TrendPoint{}
+	query := h.storage.Model(&models.Homework{}).
+		Select(`date_trunc('week', coalesce(homeworks.submitted_at, homeworks.updated_at)) as week,
+			count(*) as checked,
+			round(avg(homeworks.current_points / nullif(homeworks.max_points, 0) * 100), 1) as average_percent`).
+		Where("homeworks.teacher_id = ? and homeworks.status = 'checked'", teacherId)
+	if studentId != 0 {
+		query = query.Where("homeworks.student_id = ?", studentId)
+	}
+	result := query.Group("week").Order("week").Scan(trend)
+	if result.Error != nil {
+		return nil, errGradebookNotFound
+	}
+	return trend, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errGroupNotFound   = errors.New("group is not found")
+	errGroupNotCreated = errors.New("group is not created")
+	errGroupNotUpdated = errors.New("group is not updated")
+	errGroupNotDeleted = errors.New("group is not deleted")
+)
+
+var Group = &group{&initializers.DB}
+
+type group struct {
+	storage *initializers.PgDb
+}
+
+func (h *group) GetById(id uint) (*models.Group, error) {
+	group := &models.Group{}
+	result := h.storage.Preload("Students.Enrollments").Where("id = ?", id).Take(group)
+	if result.Error != nil {
+		return nil, errGroupNotFound
+	}
+	return group, nil
+}
+
+func (h *group) GetByTeacherId(id uint) (*[]models.Group, error) {
+	groups := &[]models.Group{}
+	result := h.storage.Preload("Students.Enrollments").Where("teacher_id = ?", id).Order("name").Find(groups)
+	if result.Error != nil {
+		return nil, errGroupNotFound
+	}
+	return groups, nil
+}
+
+func (h *group) Create(model *models.Group) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errGroupNotCreated
+	}
+	return nil
+}
+
+func (h *group) AddStudent(model *models.Group, student *models.Student) error {
+	if err := h.storage.Model(model).Association("Students").Append(student); err != nil {
+		return errGroupNotUpdated
+	}
+	return nil
+}
+
+func (h *group) RemoveStudent(model *models.Group, student *models.Student) error {
+	if err := h.storage.Model(model).Association("Students").Delete(student); err != nil {
+		return errGroupNotUpdated
+	}
+	return nil
+}
+
+func (h *group) Delete(model *models.Group) error {
+	if err := h.storage.Select("Students").Delete(model).Error; err != nil {
+		return errGroupNotDeleted
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
 	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -13,9 +15,40 @@ var (
 	errHomeworkNotCreated = errors.New("homework is not created")
 	errHomeworkNotUpdated = errors.New("homework is not updated")
 	errHomeworkNotDeleted = errors.New("homework is not deleted")
+	errAttemptNotFound    = errors.New("attempt is not found")
 )
 
-const homeworkOrder = "(case status when 'new' then 1 when 'processing' then 2 when 'finished' then 3 when 'checked' then 4 end)"
+const homeworkOrder = "(case status when 'new' then 1 when 'returned' then 2 when 'processing' then 3 when 'finished' then 4 when 'checked' then 5 end)"
+
+// homeworkUrgencyOrder puts unfinished homework with the closest due date first.
+const homeworkUrgencyOrder = "(case when status in ('finished', 'checked') then 1 else 0 end), due_at asc nulls last, " + homeworkOrder
+
+type HomeworkOrder int
+
+const (
+	OrderByStatus HomeworkOrder = iota
+	OrderByUrgency
+)
+
+func (o HomeworkOrder) clause() clause.OrderBy {
+	if o == OrderByUrgency {
+		return clause.OrderBy{Expression: clause.Expr{SQL: homeworkUrgencyOrder}}
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: homeworkOrder}}
+}
+
+// HomeworkFilter narrows down and orders homework lists.
+type HomeworkFilter struct {
+	Order HomeworkOrder
+	Type  string
+}
+
+func (f HomeworkFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Type != "" {
+		db = db.Where("type = ?", f.Type)
+	}
+	return db.Clauses(f.Order.clause())
+}
 
 var Homework = &homework{&initializers.DB}
 
@@ -32,22 +65,18 @@ func (h *homework) GetById(id uint) (*models.Homework, error) {
 	return homework, nil
 }
 
-func (h *homework) GetByTeacherId(id uint) (*[]models.Homework, error) {
+func (h *homework) GetByTeacherId(id uint, filter HomeworkFilter) (*[]models.Homework, error) {
 	homeworks := &[]models.Homework{}
-	result := h.storage.Where("teacher_id", id).Clauses(clause.OrderBy{
-		Expression: clause.Expr{SQL: homeworkOrder},
-	}).Find(homeworks)
+	result := filter.apply(h.storage.Where("teacher_id", id)).Find(homeworks)
 	if result.Error != nil {
 		return nil, errHomeworkNotFound
 	}
 	return homeworks, nil
 }
 
-func (h *homework) GetByStudentId(id uint) (*[]models.Homework, error) {
+func (h *homework) GetByStudentId(id uint, filter HomeworkFilter) (*[]models.Homework, error) {
 	homeworks := &[]models.Homework{}
-	result := h.storage.Where("student_id", id).Clauses(clause.OrderBy{
-		Expression: clause.Expr{SQL: homeworkOrder},
-	}).Find(homeworks)
+	result := filter.apply(h.storage.Where("student_id", id)).Find(homeworks)
 	if result.Error != nil {
 		return nil, errHomeworkNotFound
 	}
@@ -61,6 +90,17 @@ func (h *homework) Create(model *models.Homework) error {
 	return nil
 }
 
+// CreateMany creates homework for several students in one transaction.
+func (h *homework) CreateMany(homeworks *[]models.Homework) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(homeworks).Error
+	})
+	if err != nil {
+		return errHomeworkNotCreated
+	}
+	return nil
+}
+
 func (h *homework) Update(model *models.Homework) error {
 	if err := h.storage.Save(model).Error; err != nil {
 		return errHomeworkNotUpdated
@@ -68,6 +108,100 @@ func (h *homework) Update(model *models.Homework) error {
 	return nil
 }
 
+func (h *homework) GetAttempts(id uint) (*[]models.Attempt, error) {
+	attempts := &[]models.Attempt{}
+	result := h.storage.Preload("Submission.Files").Preload("Scores").Where("homework_id = ?", id).Order("number").Find(attempts)
+	if result.Error != nil {
+		return nil, errAttemptNotFound
+	}
+	return attempts, nil
+}
+
+// Submit saves the homework together with a new numbered attempt holding the submission.
+func (h *homework) Submit(model *models.Homework, submission *models.Submission) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Attempt{}).Where("homework_id = ?", model.Id).Count(&count).Error; err != nil {
+			return err
+		}
+		attempt := &models.Attempt{
+			HomeworkId:  model.Id,
+			Number:      uint(count) + 1,
+			Status:      model.Status,
+			Submission:  submission,
+			SubmittedAt: *model.SubmittedAt,
+		}
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		return tx.Save(model).Error
+	})
+	if err != nil {
+		return errHomeworkNotUpdated
+	}
+	return nil
+}
+
+// Reopen saves the homework the teacher opens again together with their feedback comment, if there is one.
+func (h *homework) Reopen(model *models.Homework, feedback *models.Comment) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		if feedback != nil {
+			if err := tx.Create(feedback).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(model).Error
+	})
+	if err != nil {
+		return errHomeworkNotUpdated
+	}
+	return nil
+}
+
+// Review saves the homework and records the teacher's result on its last attempt.
+// The feedback comment, if there is one, goes to the homework thread and the attempt.
+func (h *homework) Review(model *models.Homework, points *float64, scores []models.CriterionScore, feedback *models.Comment, reason string) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		if feedback != nil {
+			if err := tx.Create(feedback).Error; err != nil {
+				return err
+			}
+		}
+		attempt := &models.Attempt{}
+		err := tx.Where("homework_id = ?", model.Id).Order("number desc").Take(attempt).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Save(model).Error
+		}
+		if err != nil {
+			return err
+		}
+		reviewedAt := time.Now()
+		attempt.Status = model.Status
+		attempt.Points = points
+		if feedback != nil {
+			attempt.Feedback = feedback.Body
+		}
+		attempt.ReturnReason = reason
+		attempt.ReviewedAt = &reviewedAt
+		if err := tx.Save(attempt).Error; err != nil {
+			return err
+		}
+		for i := range scores {
+			scores[i].AttemptId = attempt.Id
+		}
+		if len(scores) > 0 {
+			if err := tx.Create(&scores).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(model).Error
+	})
+	if err != nil {
+		return errHomeworkNotUpdated
+	}
+	return nil
+}
+
 func (h *homework) DeleteByTeacherId(id uint) error {
 	if err := h.storage.Where("teacher_id", id).Delete(&models.Homework{}).Error; err != nil {
 		return errHomeworkNotDeleted
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
+)
+
+var (
+	errTemplateNotFound   = errors.New("homework template is not found")
+	errTemplateNotCreated = errors.New("homework template is not created")
+	errTemplateNotUpdated = errors.New("homework template is not updated")
+	errTemplateNotDeleted = errors.New("homework template is not deleted")
+)
+
+var HomeworkTemplate = &homeworkTemplate{&initializers.DB}
+
+type homeworkTemplate struct {
+	storage *initializers.PgDb
+}
+
+func (h *homeworkTemplate) GetById(id uint) (*models.HomeworkTemplate, error) {
+	template := &models.HomeworkTemplate{}
+	result := h.storage.Where("id = ?", id).Take(template)
+	if result.Error != nil {
+		return nil, errTemplateNotFound
+	}
+	return template, nil
+}
+
+func (h *homeworkTemplate) GetByTeacherId(id uint) (*[]models.HomeworkTemplate, error) {
+	templates := &[]models.HomeworkTemplate{}
+	result := h.storage.Where("teacher_id = ?", id).Order("name").Find(templates)
+	if result.Error != nil {
+		return nil, errTemplateNotFound
+	}
+	return templates, nil
+}
+
+func (h *homeworkTemplate) Create(model *models.HomeworkTemplate) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errTemplateNotCreated
+	}
+	return nil
+}
+
+// CreateMany creates all templates together with the new types they use, or none of them.
+func (h *homeworkTemplate) CreateMany(templates *[]models.HomeworkTemplate, types *[]models.HomeworkType) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		if len(*types) > 0 {
+			if err := tx.Create(types).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(templates).Error
+	})
+	if err != nil {
+		return errTemplateNotCreated
+	}
+	return nil
+}
+
+func (h *homeworkTemplate) Update(model *models.HomeworkTemplate) error {
+	if err := h.storage.Save(model).Error; err != nil {
+		return errTemplateNotUpdated
+	}
+	return nil
+}
+
+func (h *homeworkTemplate) Delete(model *models.HomeworkTemplate) error {
+	if err := h.storage.Delete(model).Error; err != nil {
+		return errTemplateNotDeleted
+	}
+	return nil
+}
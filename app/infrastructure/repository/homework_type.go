@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
+)
+
+var (
+	errTypeNotFound   = errors.New("homework type is not found")
+	errTypeNotCreated = errors.New("homework type is not created")
+	errTypeNotUpdated = errors.New("homework type is not updated")
+	errTypeNotDeleted = errors.New("homework type is not deleted")
+)
+
+var HomeworkType = &homeworkType{&initializers.DB}
+
+type homeworkType struct {
+	storage *initializers.PgDb
+}
+
+// GetAvailable returns the global types together with the teacher's own ones.
+func (h *homeworkType) GetAvailable(teacherId uint) (*[]models.HomeworkType, error) {
+	types := &[]models.HomeworkType{}
+	result := h.storage.Where("teacher_id is null or teacher_id = ?", teacherId).Order("name").Find(types)
+	if result.Error != nil {
+		return nil, errTypeNotFound
+	}
+	return types, nil
+}
+
+// GetAvailableToStudent returns the global types together with the own ones of every teacher the student studies or studied with.
+func (h *homeworkType) GetAvailableToStudent(studentId uint) (*[]models.HomeworkType, error) {
+	types := &[]models.HomeworkType{}
+	teachers := h.storage.Model(&models.Enrollment{}).Select("teacher_id").Where("student_id = ?", studentId)
+	result := h.storage.Where("teacher_id is null or teacher_id in (?)", teachers).Order("name, teacher_id nulls last").Find(types)
+	if result.Error != nil {
+		return nil, errTypeNotFound
+	}
+	return types, nil
+}
+
+func (h *homeworkType) GetById(id uint) (*models.HomeworkType, error) {
+	homeworkType := &models.HomeworkType{}
+	result := h.storage.Where("id = ?", id).Take(homeworkType)
+	if result.Error != nil {
+		return nil, errTypeNotFound
+	}
+	return homeworkType, nil
+}
+
+// GetByName looks the name up among the types available to the teacher.
+func (h *homeworkType) GetByName(teacherId uint, name string) (*models.HomeworkType, error) {
+	homeworkType := &models.HomeworkType{}
+	result := h.storage.Where("(teacher_id is null or teacher_id = ?) and name = ?", teacherId, name).
+		Order("teacher_id nulls last").
+		Take(homeworkType)
+	if result.Error != nil {
+		return nil, errTypeNotFound
+	}
+	return homeworkType, nil
+}
+
+func (h *homeworkType) Create(model *models.HomeworkType) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errTypeNotCreated
+	}
+	return nil
+}
+
+// Update saves the type and renames it in the teacher's homework and templates.
+func (h *homeworkType) Update(model *models.HomeworkType, oldName string) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(model).Error; err != nil {
+			return err
+		}
+		if oldName == model.Name {
+			return nil
+		}
+		err := tx.Model(&models.Homework{}).
+			Where("teacher_id = ? and type = ?", model.TeacherId, oldName).
+			Update("type", model.Name).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.HomeworkTemplate{}).
+			Where("teacher_id = ? and type = ?", model.TeacherId, oldName).
+			Update("type", model.Name).Error
+	})
+	if err != nil {
+		return errTypeNotUpdated
+	}
+	return nil
+}
+
+// Delete removes the type for good, so that its name is free for a new type or an imported template.
+func (h *homeworkType) Delete(model *models.HomeworkType) error {
+	if err := h.storage.Unscoped().Delete(model).Error; err != nil {
+		return errTypeNotDeleted
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errIdentityNotFound   = errors.New("identity is not found")
+	errIdentityNotCreated = errors.New("identity is not created")
+)
+
+var Identity = &identity{&initializers.DB}
+
+type identity struct {
+	storage *initializers.PgDb
+}
+
+// Get returns the identity the provider knows by the subject.
+func (h *identity) Get(issuer string, subject string) (*models.Identity, error) {
+	identity := &models.Identity{}
+	result := h.storage.Where("issuer = ? AND subject = ?", issuer, subject).Take(identity)
+	if result.Error != nil {
+		return nil, errIdentityNotFound
+	}
+	return identity, nil
+}
+
+func (h *identity) Create(model *models.Identity) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errIdentityNotCreated
+	}
+	return nil
+}
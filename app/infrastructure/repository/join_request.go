@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errJoinRequestNotFound   = errors.New("join request is not found")
+	errJoinRequestNotCreated = errors.New("join request is not created")
+	errJoinRequestNotUpdated = errors.New("join request is not updated")
+	errInviteNotFound        = errors.New("invite is not found")
+	errInviteNotSaved        = errors.New("invite is not saved")
+)
+
+var (
+	JoinRequest = &joinRequest{&initializers.DB}
+	Invite      = &invite{&initializers.DB}
+)
+
+type (
+	joinRequest struct {
+		storage *initializers.PgDb
+	}
+	invite struct {
+		storage *initializers.PgDb
+	}
+)
+
+func (h *joinRequest) GetById(id uint) (*models.JoinRequest, error) {
+	request := &models.JoinRequest{}
+	result := h.storage.Preload("Student").Preload("Teacher").Where("id = ?", id).Take(request)
+	if result.Error != nil {
+		return nil, errJoinRequestNotFound
+	}
+	return request, nil
+}
+
+// GetByTeacherId returns the requests sent to the teacher with the given status, oldest first.
+func (h *joinRequest) GetByTeacherId(id uint, status string) (*[]models.JoinRequest, error) {
+	requests := &[]models.JoinRequest{}
+	result := h.storage.Preload("Student").Where("teacher_id = ? and status = ?", id, status).Order("created_at").Find(requests)
+	if result.Error != nil {
+		return nil, errJoinRequestNotFound
+	}
+	return requests, nil
+}
+
+// GetByStudentId returns the requests the student sent with the given status, oldest first.
+func (h *joinRequest) GetByStudentId(id uint, status string) (*[]models.JoinRequest, error) {
+	requests := &[]models.JoinRequest{}
+	result := h.storage.Preload("Teacher").Where("student_id = ? and status = ?", id, status).Order("created_at").Find(requests)
+	if result.Error != nil {
+		return nil, errJoinRequestNotFound
+	}
+	return requests, nil
+}
+
+func (h *joinRequest) Create(model *models.JoinRequest) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errJoinRequestNotCreated
+	}
+	return nil
+}
+
+func (h *joinRequest) Update(model *models.JoinRequest) error {
+	if err := h.storage.Omit(clause.Associations).Save(model).Error; err != nil {
+		return errJoinRequestNotUpdated
+	}
+	return nil
+}
+
+// UpdateStatus moves the student's requests to the teacher from one status to another.
+func (h *joinRequest) UpdateStatus(studentId uint, teacherId uint, from string, to string) error {
+	result := h.storage.Model(&models.JoinRequest{}).
+		Where("student_id = ? and teacher_id = ? and status = ?", studentId, teacherId, from).
+		Update("status", to)
+	if result.Error != nil {
+		return errJoinRequestNotUpdated
+	}
+	return nil
+}
+
+func (h *invite) GetByTeacherId(id uint) (*models.Invite, error) {
+	invite := &models.Invite{}
+	result := h.storage.Where("teacher_id = ?", id).Take(invite)
+	if result.Error != nil {
+		return nil, errInviteNotFound
+	}
+	return invite, nil
+}
+
+func (h *invite) GetByCode(code string) (*models.Invite, error) {
+	invite := &models.Invite{}
+	result := h.storage.Where("code = ?", code).Take(invite)
+	if result.Error != nil {
+		return nil, errInviteNotFound
+	}
+	return invite, nil
+}
+
+// Save stores the teacher's invite, replacing the code of the one they had.
+func (h *invite) Save(model *models.Invite) error {
+	result := h.storage.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "teacher_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"code", "updated_at"}),
+	}).Create(model)
+	if result.Error != nil {
+		return errInviteNotSaved
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errLoginAttemptNotFound   = errors.New("login attempt is not found")
+	errLoginAttemptNotUpdated = errors.New("login attempt is not updated")
+	errLoginAttemptNotDeleted = errors.New("login attempt is not deleted")
+)
+
+var LoginAttempt = &loginAttempt{&initializers.DB}
+
+type loginAttempt struct {
+	storage *initializers.PgDb
+}
+
+// Get returns the attempts of the key, nil when it has not failed.
+func (h *loginAttempt) Get(key string) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{}
+	result := h.storage.Where("key = ?", key).Limit(1).Find(attempt)
+	if result.Error != nil {
+		return nil, errLoginAttemptNotFound
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return attempt, nil
+}
+
+// Fail counts a failure of the key in a single statement, so concurrent failures are all counted.
+func (h *loginAttempt) Fail(key string, at time.Time) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{Key: key, Failures: 1, LastFailedAt: at}
+	result := h.storage.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":       gorm.Expr("login_attempts.failures + 1"),
+				"last_failed_at": at,
+			}),
+		},
+		clause.Returning{},
+	).Create(attempt)
+	if result.Error != nil {
+		return nil, errLoginAttemptNotUpdated
+	}
+	return attempt, nil
+}
+
+func (h *loginAttempt) Lock(key string, until time.Time) error {
+	result := h.storage.Model(&models.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until)
+	if result.Error != nil {
+		return errLoginAttemptNotUpdated
+	}
+	return nil
+}
+
+func (h *loginAttempt) Delete(key string) error {
+	if err := h.storage.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error; err != nil {
+		return errLoginAttemptNotDeleted
+	}
+	return nil
+}
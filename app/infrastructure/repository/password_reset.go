@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errPasswordResetNotFound   = errors.New("password reset is not found")
+	errPasswordResetNotCreated = errors.New("password reset is not created")
+	errPasswordResetNotUsed    = errors.New("password reset is not used")
+)
+
+var PasswordReset = &passwordReset{&initializers.DB}
+
+type passwordReset struct {
+	storage *initializers.PgDb
+}
+
+func (h *passwordReset) GetByTokenHash(hash string) (*models.PasswordReset, error) {
+	reset := &models.PasswordReset{}
+	result := h.storage.Where("token_hash = ?", hash).Take(reset)
+	if result.Error != nil {
+		return nil, errPasswordResetNotFound
+	}
+	return reset, nil
+}
+
+// CountSince returns how many resets were requested for the email since the given time.
+func (h *passwordReset) CountSince(email string, since time.Time) (int64, error) {
+	var count int64
+	result := h.storage.Model(&models.PasswordReset{}).Where("email = ? AND created_at > ?", email, since).Count(&count)
+	if result.Error != nil {
+		return 0, errPasswordResetNotFound
+	}
+	return count, nil
+}
+
+func (h *passwordReset) Create(model *models.PasswordReset) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errPasswordResetNotCreated
+	}
+	return nil
+}
+
+// Use marks every unused reset of the account as used, so the token works once and the older ones stop working.
+// It fails when the reset has been used already.
+func (h *passwordReset) Use(model *models.PasswordReset) error {
+	now := time.Now()
+	result := h.storage.Model(&models.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", model.Id).
+		Update("used_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return errPasswordResetNotUsed
+	}
+	model.UsedAt = &now
+	result = h.storage.Model(&models.PasswordReset{}).
+		Where("email = ? AND role = ? AND used_at IS NULL", model.Email, model.Role).
+		Update("used_at", now)
+	if result.Error != nil {
+		return errPasswordResetNotUsed
+	}
+	return nil
+}
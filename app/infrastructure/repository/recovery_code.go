@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
+)
+
+var (
+	errRecoveryCodeNotFound   = errors.New("recovery code is not found")
+	errRecoveryCodeNotCreated = errors.New("recovery codes are not created")
+	errRecoveryCodeNotDeleted = errors.New("recovery codes are not deleted")
+)
+
+var RecoveryCode = &recoveryCode{&initializers.DB}
+
+type recoveryCode struct {
+	storage *initializers.PgDb
+}
+
+// CountUnused returns how many of the teacher's codes are left.
+func (h *recoveryCode) CountUnused(teacherId uint) (int64, error) {
+	var count int64
+	result := h.storage.Model(&models.RecoveryCode{}).Where("teacher_id = ? AND used_at IS NULL", teacherId).Count(&count)
+	if result.Error != nil {
+		return 0, errRecoveryCodeNotFound
+	}
+	return count, nil
+}
+
+// Replace gives the teacher new codes; the ones they had stop working.
+func (h *recoveryCode) Replace(teacherId uint, codes *[]models.RecoveryCode) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("teacher_id = ?", teacherId).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(codes).Error
+	})
+	if err != nil {
+		return errRecoveryCodeNotCreated
+	}
+	return nil
+}
+
+// Use marks the teacher's unused code with the hash as used. It fails when there is no such code.
+func (h *recoveryCode) Use(teacherId uint, hash string) error {
+	result := h.storage.Model(&models.RecoveryCode{}).
+		Where("teacher_id = ? AND code_hash = ? AND used_at IS NULL", teacherId, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		return errRecoveryCodeNotFound
+	}
+	return nil
+}
+
+func (h *recoveryCode) DeleteByTeacherId(teacherId uint) error {
+	if err := h.storage.Where("teacher_id = ?", teacherId).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return errRecoveryCodeNotDeleted
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm"
+)
+
+var (
+	errRubricNotFound   = errors.New("rubric is not found")
+	errRubricNotCreated = errors.New("rubric is not created")
+	errRubricNotDeleted = errors.New("rubric is not deleted")
+)
+
+var Rubric = &rubric{&initializers.DB}
+
+type rubric struct {
+	storage *initializers.PgDb
+}
+
+func criteriaOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+func (h *rubric) GetById(id uint) (*models.Rubric, error) {
+	rubric := &models.Rubric{}
+	result := h.storage.Preload("Criteria", criteriaOrder).Where("id = ?", id).Take(rubric)
+	if result.Error != nil {
+		return nil, errRubricNotFound
+	}
+	return rubric, nil
+}
+
+func (h *rubric) GetByTeacherId(id uint) (*[]models.Rubric, error) {
+	rubrics := &[]models.Rubric{}
+	result := h.storage.Preload("Criteria", criteriaOrder).Where("teacher_id = ?", id).Order("name").Find(rubrics)
+	if result.Error != nil {
+		return nil, errRubricNotFound
+	}
+	return rubrics, nil
+}
+
+func (h *rubric) Create(model *models.Rubric) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errRubricNotCreated
+	}
+	return nil
+}
+
+// Delete removes the rubric. Homework graded with it keeps its scores and falls back to plain points.
+func (h *rubric) Delete(model *models.Rubric) error {
+	err := h.storage.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Homework{}).Where("rubric_id = ?", model.Id).Update("rubric_id", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Select("Criteria").Delete(model).Error
+	})
+	if err != nil {
+		return errRubricNotDeleted
+	}
+	return nil
+}
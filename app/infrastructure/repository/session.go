@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errSessionNotFound   = errors.New("session is not found")
+	errSessionNotCreated = errors.New("session is not created")
+	errSessionNotUpdated = errors.New("session is not updated")
+	errSessionNotRevoked = errors.New("session is not revoked")
+)
+
+var Session = &session{&initializers.DB}
+
+type session struct {
+	storage *initializers.PgDb
+}
+
+func (h *session) GetById(id uint) (*models.Session, error) {
+	session := &models.Session{}
+	result := h.storage.Where("id = ?", id).Take(session)
+	if result.Error != nil {
+		return nil, errSessionNotFound
+	}
+	return session, nil
+}
+
+func (h *session) GetByRefreshHash(hash string) (*models.Session, error) {
+	session := &models.Session{}
+	result := h.storage.Where("refresh_hash = ?", hash).Take(session)
+	if result.Error != nil {
+		return nil, errSessionNotFound
+	}
+	return session, nil
+}
+
+// GetByPreviousHash finds the session whose refresh token has already been rotated away from the hash.
+func (h *session) GetByPreviousHash(hash string) (*models.Session, error) {
+	session := &models.Session{}
+	result := h.storage.Where("previous_hash = ?", hash).Take(session)
+	if result.Error != nil {
+		return nil, errSessionNotFound
+	}
+	return session, nil
+}
+
+// GetActive returns the sessions of the account in any of the roles that are still signed in, the latest used first.
+func (h *session) GetActive(roles []string, accountId uint) (*[]models.Session, error) {
+	sessions := &[]models.Session{}
+	result := h.storage.
+		Where("role IN ? AND account_id = ? AND revoked_at IS NULL AND expires_at > ?", roles, accountId, time.Now()).
+		Order("last_used_at desc").
+		Find(sessions)
+	if result.Error != nil {
+		return nil, errSessionNotFound
+	}
+	return sessions, nil
+}
+
+func (h *session) Create(model *models.Session) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errSessionNotCreated
+	}
+	return nil
+}
+
+// Rotate replaces the refresh token and the role of the session, unless another request has rotated it first.
+func (h *session) Rotate(model *models.Session, oldHash string) error {
+	result := h.storage.Model(model).Where("refresh_hash = ? AND revoked_at IS NULL", oldHash).Updates(map[string]interface{}{
+		"role":          model.Role,
+		"refresh_hash":  model.RefreshHash,
+		"previous_hash": oldHash,
+		"user_agent":    model.UserAgent,
+		"ip":            model.Ip,
+		"expires_at":    model.ExpiresAt,
+		"last_used_at":  model.LastUsedAt,
+	})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return errSessionNotUpdated
+	}
+	return nil
+}
+
+func (h *session) Revoke(model *models.Session) error {
+	now := time.Now()
+	if err := h.storage.Model(model).Update("revoked_at", now).Error; err != nil {
+		return errSessionNotRevoked
+	}
+	model.RevokedAt = &now
+	return nil
+}
+
+// RevokeAll signs the account out on every device, whatever of the roles it acts in there.
+func (h *session) RevokeAll(roles []string, accountId uint) error {
+	result := h.storage.Model(&models.Session{}).
+		Where("role IN ? AND account_id = ? AND revoked_at IS NULL", roles, accountId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return errSessionNotRevoked
+	}
+	return nil
+}
+
+// RevokeAllBut signs the account out on every device but the one with the session.
+func (h *session) RevokeAllBut(roles []string, accountId uint, sessionId uint) error {
+	result := h.storage.Model(&models.Session{}).
+		Where("role IN ? AND account_id = ? AND id <> ? AND revoked_at IS NULL", roles, accountId, sessionId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return errSessionNotRevoked
+	}
+	return nil
+}
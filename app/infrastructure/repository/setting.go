@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errSettingNotFound = errors.New("setting is not found")
+	errSettingNotSaved = errors.New("setting is not saved")
+)
+
+var Setting = &setting{&initializers.DB}
+
+type setting struct {
+	storage *initializers.PgDb
+}
+
+func (h *setting) Get(name string) (*models.Setting, error) {
+	setting := &models.Setting{}
+	result := h.storage.Where("name = ?", name).Take(setting)
+	if result.Error != nil {
+		return nil, errSettingNotFound
+	}
+	return setting, nil
+}
+
+func (h *setting) Save(model *models.Setting) error {
+	if err := h.storage.Save(model).Error; err != nil {
+		return errSettingNotSaved
+	}
+	return nil
+}
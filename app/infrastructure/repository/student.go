@@ -5,6 +5,7 @@ import (
 
 	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
 	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -22,25 +23,51 @@ type student struct {
 
 func (h *student) GetById(id uint) (*models.Student, error) {
 	student := &models.Student{}
-	result := h.storage.Where("id = ?", id).Take(student)
+	result := h.storage.Preload("Enrollments").Where("id = ?", id).Take(student)
 	if result.Error != nil {
 		return nil, errStudentNotFound
 	}
 	return student, nil
 }
 
+// GetByTeacherId returns the students who study with the teacher now.
 func (h *student) GetByTeacherId(id uint) (*[]models.Student, error) {
 	students := &[]models.Student{}
-	result := h.storage.Where("teacher_id = ?", id).Find(students)
+	result := h.storage.Preload("Enrollments").
+		Joins("join enrollments on enrollments.student_id = students.id and enrollments.deleted_at is null").
+		Where("enrollments.teacher_id = ? and enrollments.status = ?", id, models.EnrollmentActive).
+		Order("students.name").
+		Find(students)
 	if result.Error != nil {
 		return nil, errStudentNotFound
 	}
 	return students, nil
 }
 
-func (h *student) GetByEmail(email string) (*models.Student, error) {
+func (h *student) GetByIds(ids []uint) (*[]models.Student, error) {
+	students := &[]models.Student{}
+	result := h.storage.Preload("Enrollments").Where("id in ?", ids).Find(students)
+	if result.Error != nil {
+		return nil, errStudentNotFound
+	}
+	return students, nil
+}
+
+// Search returns the students whose name or email contains the query, deactivated ones included.
+func (h *student) Search(query string) (*[]models.Student, error) {
+	students := &[]models.Student{}
+	pattern := "%" + query + "%"
+	result := h.storage.Where("name ilike ? or email ilike ?", pattern, pattern).Order("name").Find(students)
+	if result.Error != nil {
+		return nil, errStudentNotFound
+	}
+	return students, nil
+}
+
+// GetByUserId returns the studying role of the user.
+func (h *student) GetByUserId(userId uint) (*models.Student, error) {
 	student := &models.Student{}
-	result := h.storage.Where("email = ?", email).Take(student)
+	result := h.storage.Preload("Enrollments").Where("user_id = ?", userId).Take(student)
 	if result.Error != nil {
 		return nil, errStudentNotFound
 	}
@@ -55,7 +82,7 @@ func (h *student) Create(model *models.Student) error {
 }
 
 func (h *student) Update(model *models.Student) error {
-	if err := h.storage.Save(model).Error; err != nil {
+	if err := h.storage.Omit(clause.Associations).Save(model).Error; err != nil {
 		return errStudentNotUpdated
 	}
 	return nil
@@ -65,5 +92,11 @@ func (h *student) Delete(model *models.Student) error {
 	if err := h.storage.Delete(model).Error; err != nil {
 		return errStudentNotDeleted
 	}
+	if err := h.storage.Where("student_id = ?", model.Id).Delete(&models.JoinRequest{}).Error; err != nil {
+		return errStudentNotDeleted
+	}
+	if err := h.storage.Where("student_id = ?", model.Id).Delete(&models.Enrollment{}).Error; err != nil {
+		return errStudentNotDeleted
+	}
 	return nil
 }
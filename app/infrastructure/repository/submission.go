@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errFileNotFound = errors.New("file is not found")
+)
+
+var Submission = &submission{&initializers.DB}
+
+type submission struct {
+	storage *initializers.PgDb
+}
+
+func (h *submission) GetFile(homeworkId uint, id uint) (*models.SubmissionFile, error) {
+	file := &models.SubmissionFile{}
+	result := h.storage.Joins("join submissions on submissions.id = submission_files.submission_id").
+		Where("submission_files.id = ? and submissions.homework_id = ?", id, homeworkId).
+		Take(file)
+	if result.Error != nil {
+		return nil, errFileNotFound
+	}
+	return file, nil
+}
@@ -5,11 +5,13 @@ import (
 
 	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
 	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"gorm.io/gorm/clause"
 )
 
 var (
 	errTeacherNotFound   = errors.New("teacher is not found")
 	errTeacherNotCreated = errors.New("teacher is not created")
+	errTeacherNotUpdated = errors.New("teacher is not updated")
 	errTeacherNotDeleted = errors.New("teacher is not deleted")
 )
 
@@ -19,9 +21,21 @@ type teacher struct {
 	storage *initializers.PgDb
 }
 
+// GetList returns the teachers students can study with.
 func (h *teacher) GetList() (*[]models.Teacher, error) {
 	teachers := &[]models.Teacher{}
-	result := h.storage.Find(teachers)
+	result := h.storage.Where("deactivated_at is null").Order("name").Find(teachers)
+	if result.Error != nil {
+		return nil, errTeacherNotFound
+	}
+	return teachers, nil
+}
+
+// Search returns the teachers whose name or email contains the query, deactivated ones included.
+func (h *teacher) Search(query string) (*[]models.Teacher, error) {
+	teachers := &[]models.Teacher{}
+	pattern := "%" + query + "%"
+	result := h.storage.Where("name ilike ? or email ilike ?", pattern, pattern).Order("name").Find(teachers)
 	if result.Error != nil {
 		return nil, errTeacherNotFound
 	}
@@ -37,9 +51,10 @@ func (h *teacher) GetById(id uint) (*models.Teacher, error) {
 	return teacher, nil
 }
 
-func (h *teacher) GetByEmail(email string) (*models.Teacher, error) {
+// GetByUserId returns the teaching role of the user.
+func (h *teacher) GetByUserId(userId uint) (*models.Teacher, error) {
 	teacher := &models.Teacher{}
-	result := h.storage.Where("email = ?", email).Take(teacher)
+	result := h.storage.Where("user_id = ?", userId).Take(teacher)
 	if result.Error != nil {
 		return nil, errTeacherNotFound
 	}
@@ -53,11 +68,39 @@ func (h *teacher) Create(model *models.Teacher) error {
 	return nil
 }
 
+func (h *teacher) Update(model *models.Teacher) error {
+	if err := h.storage.Omit(clause.Associations).Save(model).Error; err != nil {
+		return errTeacherNotUpdated
+	}
+	return nil
+}
+
+// UseTotpStep remembers the period of the accepted code. It fails when a code of that period or a later one was accepted already.
+func (h *teacher) UseTotpStep(model *models.Teacher, step int64) error {
+	result := h.storage.Model(&models.Teacher{}).
+		Where("id = ? AND totp_last_step < ?", model.Id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return errTeacherNotUpdated
+	}
+	model.TotpLastStep = step
+	return nil
+}
+
 func (h *teacher) Delete(model *models.Teacher) error {
 	if err := h.storage.Delete(model).Error; err != nil {
 		return errTeacherNotDeleted
 	}
-	if err := h.storage.Model(&models.Student{}).Where("teacher_id", model.Id).Update("teacher_id", nil).Error; err != nil {
+	if err := h.storage.Where("teacher_id = ?", model.Id).Delete(&models.Enrollment{}).Error; err != nil {
+		return errTeacherNotDeleted
+	}
+	if err := h.storage.Where("teacher_id = ?", model.Id).Delete(&models.JoinRequest{}).Error; err != nil {
+		return errTeacherNotDeleted
+	}
+	if err := h.storage.Where("teacher_id = ?", model.Id).Delete(&models.Invite{}).Error; err != nil {
+		return errTeacherNotDeleted
+	}
+	if err := h.storage.Where("teacher_id = ?", model.Id).Delete(&models.RecoveryCode{}).Error; err != nil {
 		return errTeacherNotDeleted
 	}
 	return nil
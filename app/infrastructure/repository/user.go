@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+)
+
+var (
+	errUserNotFound   = errors.New("user is not found")
+	errUserNotCreated = errors.New("user is not created")
+	errUserNotUpdated = errors.New("user is not updated")
+	errUserNotDeleted = errors.New("user is not deleted")
+)
+
+var User = &user{&initializers.DB}
+
+type user struct {
+	storage *initializers.PgDb
+}
+
+func (h *user) GetById(id uint) (*models.User, error) {
+	user := &models.User{}
+	result := h.storage.Where("id = ?", id).Take(user)
+	if result.Error != nil {
+		return nil, errUserNotFound
+	}
+	return user, nil
+}
+
+func (h *user) GetByEmail(email string) (*models.User, error) {
+	user := &models.User{}
+	result := h.storage.Where("email = ?", email).Take(user)
+	if result.Error != nil {
+		return nil, errUserNotFound
+	}
+	return user, nil
+}
+
+// IsVerified reports whether a user with the email has confirmed it.
+func (h *user) IsVerified(email string) bool {
+	var count int64
+	h.storage.Model(&models.User{}).Where("email = ? and verified_at is not null", email).Count(&count)
+	return count > 0
+}
+
+func (h *user) Create(model *models.User) error {
+	if err := h.storage.Create(model).Error; err != nil {
+		return errUserNotCreated
+	}
+	return nil
+}
+
+func (h *user) Update(model *models.User) error {
+	if err := h.storage.Save(model).Error; err != nil {
+		return errUserNotUpdated
+	}
+	return nil
+}
+
+func (h *user) Delete(model *models.User) error {
+	if err := h.storage.Delete(model).Error; err != nil {
+		return errUserNotDeleted
+	}
+	if err := h.storage.Where("user_id = ?", model.Id).Delete(&models.Identity{}).Error; err != nil {
+		return errUserNotDeleted
+	}
+	if err := h.storage.Where("user_id = ?", model.Id).Delete(&models.ApiToken{}).Error; err != nil {
+		return errUserNotDeleted
+	}
+	return nil
+}
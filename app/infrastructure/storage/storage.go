@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var (
+	errFileNotFound = errors.New("file is not found")
+	errFileNotSaved = errors.New("file is not saved")
+)
+
+// Storage keeps uploaded files under slash-separated keys.
+type Storage interface {
+	Save(key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+type local struct {
+	root string
+}
+
+// NewLocal returns a storage that keeps files in the root directory.
+func NewLocal(root string) Storage {
+	return &local{root: root}
+}
+
+func (l *local) path(key string) string {
+	return filepath.Join(l.root, filepath.Clean("/"+filepath.FromSlash(key)))
+}
+
+func (l *local) Save(key string, r io.Reader) error {
+	path := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errFileNotSaved
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return errFileNotSaved
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, r); err != nil {
+		return errFileNotSaved
+	}
+	return nil
+}
+
+func (l *local) Open(key string) (io.ReadCloser, error) {
+	file, err := os.Open(l.path(key))
+	if err != nil {
+		return nil, errFileNotFound
+	}
+	return file, nil
+}
+
+func (l *local) Delete(key string) error {
+	if err := os.Remove(l.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
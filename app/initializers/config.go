@@ -12,14 +12,28 @@ import (
 )
 
 type Config struct {
-	PgHost         string `env:"PG_HOST"`
-	PgUser         string `env:"PG_USER"`
-	PgPassword     string `env:"PG_PASSWORD"`
-	PgDb           string `env:"PG_DB"`
-	PgPort         string `env:"PG_PORT"`
-	JwtSecretKey   string `env:"JWT_SECRET_KEY"`
-	ContextKeyUser string `env:"CONTEXT_KEY_USER"`
-	JwtCookieKey   string `env:"JWT_COOKIE_KEY"`
+	PgHost           string `env:"PG_HOST"`
+	PgUser           string `env:"PG_USER"`
+	PgPassword       string `env:"PG_PASSWORD"`
+	PgDb             string `env:"PG_DB"`
+	PgPort           string `env:"PG_PORT"`
+	JwtSecretKey     string `env:"JWT_SECRET_KEY"`
+	ContextKeyUser   string `env:"CONTEXT_KEY_USER"`
+	JwtCookieKey     string `env:"JWT_COOKIE_KEY"`
+	RefreshCookieKey string `env:"REFRESH_COOKIE_KEY" default:"refresh"`
+	TotpCookieKey    string `env:"TOTP_COOKIE_KEY" default:"totp"`
+	StoragePath      string `env:"STORAGE_PATH" default:"storage"`
+	AdminEmail       string `env:"ADMIN_EMAIL"`
+	AdminPassword    string `env:"ADMIN_PASSWORD"`
+	LockoutStore     string `env:"LOCKOUT_STORE" default:"postgres"`
+	OidcIssuer       string `env:"OIDC_ISSUER"`
+	OidcClientId     string `env:"OIDC_CLIENT_ID"`
+	OidcClientSecret string `env:"OIDC_CLIENT_SECRET"`
+	OidcName         string `env:"OIDC_NAME" default:"your school account"`
+	OidcRoleClaim    string `env:"OIDC_ROLE_CLAIM" default:"roles"`
+	OidcTeacherRole  string `env:"OIDC_TEACHER_ROLE" default:"teacher"`
+	OidcStudentRole  string `env:"OIDC_STUDENT_ROLE" default:"student"`
+	OidcCookieKey    string `env:"OIDC_COOKIE_KEY" default:"oidc"`
 }
 
 var (
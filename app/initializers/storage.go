@@ -0,0 +1,9 @@
+package initializers
+
+import "github.com/MikhailR1337/task-sync-x/app/infrastructure/storage"
+
+var Storage storage.Storage
+
+func InitStorage() {
+	Storage = storage.NewLocal(Cfg.StoragePath)
+}
@@ -1,9 +1,22 @@
 package initializers
 
-import "github.com/go-playground/validator/v10"
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
 
 var Validator *validator.Validate
 
 func InitValidator() {
 	Validator = validator.New()
+	// report invalid fields by the names clients send them with
+	Validator.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
 }
@@ -10,7 +10,7 @@
             }
         }
         if (!input) {
-            return;
+            continue;
         }
     
         const reload = async () => {
@@ -18,13 +18,14 @@
         }
         const sendData = async () => {
             const formData = new FormData(form);
+            const isMultipart = form.enctype === 'multipart/form-data';
             try {
                 await fetch(form.action, {
                     method: input.value,
-                    headers: {
+                    headers: isMultipart ? {} : {
                         'Content-Type': 'application/json',
                     },
-                    body: JSON.stringify(Object.fromEntries(formData)),
+                    body: isMultipart ? formData : JSON.stringify(Object.fromEntries(formData)),
                 })
                 await reload();
             } catch(e) {
@@ -0,0 +1,335 @@
+package account
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	Teacher = "teacher"
+	Student = "student"
+	Admin   = "admin"
+)
+
+var (
+	ErrUnauthorized   = failure.New(failure.Unauthorized, "sign in to continue")
+	ErrBadCredentials = failure.New(failure.Unauthorized, "email or password is incorrect")
+	ErrDeactivated    = failure.New(failure.Forbidden, "your account is deactivated, contact the administrator")
+	ErrConflict       = failure.New(failure.Conflict, "oops... we already have this email")
+	ErrTeacher        = failure.New(failure.Invalid, "choose one of the teachers")
+)
+
+// Actor is the signed in user, acting as a teacher or a student, or the signed in admin.
+type Actor struct {
+	// Role is the role the actor acts in.
+	Role string
+	// User is who signs in, nil for admins. Teacher and Student are the user's roles, nil for the ones they do not have.
+	User    *models.User
+	Teacher *models.Teacher
+	Student *models.Student
+	Admin   *models.Admin
+	// SessionId is the session the actor signed in with, TokenId the personal access token instead.
+	SessionId uint
+	TokenId   uint
+}
+
+func (a *Actor) IsTeacher() bool {
+	return a.Role == Teacher
+}
+
+func (a *Actor) IsAdmin() bool {
+	return a.Role == Admin
+}
+
+// Id is the id of the teacher, student or admin the actor acts as.
+func (a *Actor) Id() uint {
+	switch a.Role {
+	case Teacher:
+		return a.Teacher.Id
+	case Student:
+		return a.Student.Id
+	case Admin:
+		return a.Admin.Id
+	}
+	return 0
+}
+
+// AccountId is the id of the user, or of the admin, whatever role they act in.
+func (a *Actor) AccountId() uint {
+	if a.IsAdmin() {
+		return a.Admin.Id
+	}
+	return a.User.Id
+}
+
+func (a *Actor) Name() string {
+	if a.IsAdmin() {
+		return a.Admin.Name
+	}
+	return a.User.Name
+}
+
+func (a *Actor) Email() string {
+	if a.IsAdmin() {
+		return a.Admin.Email
+	}
+	return a.User.Email
+}
+
+func (a *Actor) password() string {
+	if a.IsAdmin() {
+		return a.Admin.Password
+	}
+	return a.User.Password
+}
+
+func (a *Actor) passwordChangedAt() *time.Time {
+	if a.IsAdmin() {
+		return a.Admin.PasswordChangedAt
+	}
+	return a.User.PasswordChangedAt
+}
+
+// Verified reports whether the user has confirmed their email. Admins are created from the configuration and always are.
+func (a *Actor) Verified() bool {
+	if a.IsAdmin() {
+		return true
+	}
+	return a.User.VerifiedAt != nil
+}
+
+// Deactivated reports whether an admin has deactivated the role the actor acts in.
+// A user whose every role is deactivated has none to act in.
+func (a *Actor) Deactivated() bool {
+	switch a.Role {
+	case Teacher:
+		return a.Teacher.DeactivatedAt != nil
+	case Student:
+		return a.Student.DeactivatedAt != nil
+	case Admin:
+		return false
+	}
+	return true
+}
+
+// Resolve finds the account the token was issued for at issuedAt. Deactivated roles are signed out,
+// and so are the sessions started before the password was last changed.
+func Resolve(id uint, role string, issuedAt time.Time) (*Actor, error) {
+	actor, err := findById(id, role)
+	if err != nil || actor.Deactivated() {
+		return nil, ErrUnauthorized
+	}
+	if changedAt := actor.passwordChangedAt(); changedAt != nil && issuedAt.Unix() < changedAt.Unix() {
+		return nil, ErrUnauthorized
+	}
+	return actor, nil
+}
+
+// findById finds the admin, or the user acting in the role, which they have to have.
+func findById(id uint, role string) (*Actor, error) {
+	if role == Admin {
+		admin, err := repository.Admin.GetById(id)
+		if err != nil {
+			return nil, ErrUnauthorized
+		}
+		return &Actor{Role: role, Admin: admin}, nil
+	}
+	user, err := repository.User.GetById(id)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	actor := withRoles(user)
+	if !actor.Has(role) {
+		return nil, ErrUnauthorized
+	}
+	actor.Role = role
+	return actor, nil
+}
+
+// find returns the accounts with the email: the user first, then the admin.
+func find(email string) []*Actor {
+	actors := []*Actor{}
+	if user, err := repository.User.GetByEmail(email); err == nil {
+		actors = append(actors, withRoles(user))
+	}
+	if admin, err := repository.Admin.GetByEmail(email); err == nil {
+		actors = append(actors, &Actor{Role: Admin, Admin: admin})
+	}
+	return actors
+}
+
+// withRoles loads the roles of the user, who acts in the one they sign in with by default.
+func withRoles(user *models.User) *Actor {
+	actor := &Actor{User: user}
+	if teacher, err := repository.Teacher.GetByUserId(user.Id); err == nil {
+		actor.Teacher = teacher
+	}
+	if student, err := repository.Student.GetByUserId(user.Id); err == nil {
+		actor.Student = student
+	}
+	actor.Role = actor.defaultRole()
+	return actor
+}
+
+// FromClaims finds the account of the verified token claims. Tokens made for something else, like confirming an email, do not sign in.
+func FromClaims(claims jwt.MapClaims) (*Actor, error) {
+	if _, ok := claims["purpose"]; ok {
+		return nil, ErrUnauthorized
+	}
+	sub, _ := claims["sub"].(string)
+	id, err := strconv.ParseUint(sub, 10, 32)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	role, _ := claims["roles"].(string)
+	issuedAt, _ := claims["iat"].(float64)
+	actor, err := Resolve(uint(id), role, time.Unix(int64(issuedAt), 0))
+	if err != nil {
+		return nil, err
+	}
+	sessionId, _ := claims["sid"].(float64)
+	actor.SessionId = uint(sessionId)
+	tokenId, _ := claims["tid"].(float64)
+	actor.TokenId = uint(tokenId)
+	return actor, nil
+}
+
+// Register creates the user with their first role and mails the link that confirms their email.
+// link turns the token into the address the user follows.
+func Register(req forms.RegistrateRequest, link func(token string) string) (*Actor, error) {
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	if _, err := repository.User.GetByEmail(req.Email); err == nil {
+		return nil, ErrConflict
+	}
+	password, err := utilities.HashPassword(req.Password)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	user := &models.User{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: password,
+	}
+	if err := repository.User.Create(user); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	actor := &Actor{User: user}
+	if err := actor.addRole(req.Role); err != nil {
+		return nil, err
+	}
+	actor.Role = req.Role
+	if err := sendVerification(actor, link); err != nil {
+		logrus.WithError(err)
+	}
+	return actor, nil
+}
+
+// Login checks the password of the user or admin with the email. Users act as teachers when they can and as students otherwise.
+// Failed attempts from the account or the IP address make the next ones wait and then lock them out for a while.
+func Login(req forms.LoginRequest, ip string) (*Actor, error) {
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	key := passwordKey(req.Email)
+	if err := checkLogin(key, ip); err != nil {
+		return nil, err
+	}
+	actors := find(req.Email)
+	for _, actor := range actors {
+		if !utilities.CheckPasswordHash(req.Password, actor.password()) {
+			continue
+		}
+		if actor.Deactivated() {
+			return nil, ErrDeactivated
+		}
+		succeedLogin(key)
+		return actor, nil
+	}
+	if len(actors) == 0 {
+		failLogin(nil, key, ip)
+	} else {
+		failLogin(actors[0], key, ip)
+	}
+	return nil, ErrBadCredentials
+}
+
+// EnsureAdmin creates the configured admin unless they exist. Without an email no admin is created.
+func EnsureAdmin(email string, password string) error {
+	if email == "" {
+		return nil
+	}
+	if _, err := repository.Admin.GetByEmail(email); err == nil {
+		return nil
+	}
+	hash, err := utilities.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return repository.Admin.Create(&models.Admin{
+		Name:     "Administrator",
+		Email:    email,
+		Password: hash,
+	})
+}
+
+func Teachers() ([]models.Teacher, error) {
+	teachers, err := repository.Teacher.GetList()
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *teachers, nil
+}
+
+// Students returns the students of the signed in teacher.
+func Students(actor *Actor) ([]models.Student, error) {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
+	}
+	students, err := repository.Student.GetByTeacherId(actor.Teacher.Id)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *students, nil
+}
+
+// Delete removes the user together with their roles and homework.
+func Delete(actor *Actor) error {
+	if actor.IsAdmin() {
+		return failure.ErrForbidden
+	}
+	if err := SignOutEverywhere(actor); err != nil {
+		return err
+	}
+	if actor.Teacher != nil {
+		if err := repository.Teacher.Delete(actor.Teacher); err != nil {
+			return failure.ErrSomethingWrong
+		}
+		if err := repository.Homework.DeleteByTeacherId(actor.Teacher.Id); err != nil {
+			logrus.WithError(err)
+		}
+	}
+	if actor.Student != nil {
+		if err := repository.Student.Delete(actor.Student); err != nil {
+			return failure.ErrSomethingWrong
+		}
+		if err := repository.Homework.DeleteByStudentId(actor.Student.Id); err != nil {
+			logrus.WithError(err)
+		}
+	}
+	if err := repository.User.Delete(actor.User); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package account
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// ApiTokenPrefix starts every personal access token, which tells them apart from access tokens and secret scanners find them by.
+const ApiTokenPrefix = "tsx_"
+
+// The scopes of personal access tokens: read lets the token look, write lets it change things too.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+var (
+	ErrApiToken      = failure.New(failure.Unauthorized, "the personal access token has expired or been revoked")
+	ErrApiTokenScope = failure.New(failure.Forbidden, "the personal access token can only read")
+	ErrApiTokenOwner = failure.New(failure.Forbidden, "personal access tokens cannot manage the account, sign in to do it")
+)
+
+// NewApiToken is the personal access token just made. Secret is the token itself, shown this once.
+type NewApiToken struct {
+	Token  *models.ApiToken
+	Secret string
+}
+
+// CreateApiToken makes a personal access token that acts for the user in the role they act in now.
+func CreateApiToken(actor *Actor, req forms.ApiTokenRequest) (*NewApiToken, error) {
+	if actor.IsAdmin() {
+		return nil, failure.ErrForbidden
+	}
+	if actor.TokenId != 0 {
+		return nil, ErrApiTokenOwner
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	secret = ApiTokenPrefix + secret
+	token := &models.ApiToken{
+		UserId:    actor.AccountId(),
+		Role:      actor.Role,
+		Name:      req.Name,
+		Hash:      hashSecret(secret),
+		Scope:     req.Scope,
+		ExpiresAt: time.Now().AddDate(0, 0, req.Days),
+	}
+	if err := repository.ApiToken.Create(token); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return &NewApiToken{Token: token, Secret: secret}, nil
+}
+
+// ApiTokens returns the personal access tokens of the user that still work, the latest made first.
+func ApiTokens(actor *Actor) ([]models.ApiToken, error) {
+	if actor.IsAdmin() {
+		return nil, failure.ErrForbidden
+	}
+	tokens, err := repository.ApiToken.GetActive(actor.AccountId())
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *tokens, nil
+}
+
+// RevokeApiToken stops one of the personal access tokens of the user from working.
+func RevokeApiToken(actor *Actor, id uint) error {
+	token, err := repository.ApiToken.GetById(id)
+	if err != nil {
+		return failure.ErrNotFound
+	}
+	if err := policy.ApiToken(actor, policy.Delete, token); err != nil {
+		return err
+	}
+	if err := repository.ApiToken.Revoke(token); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// CheckApiToken finds the personal access token and returns the claims an access token of its user and role would have,
+// so the request is signed in the same way. write tells whether the request changes things, which read tokens cannot do.
+func CheckApiToken(secret string, write bool) (jwt.MapClaims, error) {
+	if !strings.HasPrefix(secret, ApiTokenPrefix) {
+		return nil, ErrApiToken
+	}
+	token, err := repository.ApiToken.GetByHash(hashSecret(secret))
+	now := time.Now()
+	if err != nil || !token.Active(now) {
+		return nil, ErrApiToken
+	}
+	if write && token.Scope != ScopeWrite {
+		return nil, ErrApiTokenScope
+	}
+	if err := repository.ApiToken.Touch(token, now); err != nil {
+		logrus.WithError(err)
+	}
+	return jwt.MapClaims{
+		"sub":   strconv.FormatUint(uint64(token.UserId), 10),
+		"roles": token.Role,
+		"tid":   float64(token.Id),
+		"iat":   float64(now.Unix()),
+	}, nil
+}
+
+// CheckAccountAccess keeps requests signed in with a personal access token away from the profile, the password, the roles,
+// the sessions, two-factor authentication and the tokens themselves, so a leaked token cannot take the account over.
+// It only looks at the claims, before the account is loaded.
+func CheckAccountAccess(claims jwt.MapClaims) error {
+	if _, ok := claims["tid"]; ok {
+		return ErrApiTokenOwner
+	}
+	return nil
+}
+
+// revokeApiTokens stops every personal access token of the user from working.
+func (a *Actor) revokeApiTokens() error {
+	if a.IsAdmin() {
+		return nil
+	}
+	return repository.ApiToken.RevokeAll(a.User.Id)
+}
@@ -0,0 +1,113 @@
+package account
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/lockout"
+	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/sirupsen/logrus"
+)
+
+// How failed sign ins slow down and lock out an account and an IP address. Many people can share an address,
+// so it gets more tries, while an account is locked sooner and its owner is told by email.
+var (
+	AccountLockout = lockout.Policy{
+		Free:      3,
+		Delay:     time.Second,
+		MaxDelay:  time.Minute,
+		LockAfter: 10,
+		LockFor:   15 * time.Minute,
+		Window:    time.Hour,
+	}
+	IpLockout = lockout.Policy{
+		Free:      20,
+		Delay:     time.Second,
+		MaxDelay:  time.Minute,
+		LockAfter: 100,
+		LockFor:   15 * time.Minute,
+		Window:    time.Hour,
+	}
+)
+
+var (
+	accountLogins = lockout.New(lockout.NewMemoryStore(), AccountLockout)
+	ipLogins      = lockout.New(lockout.NewMemoryStore(), IpLockout)
+)
+
+// CountLoginsIn keeps the failed sign ins in the store. They are kept in memory until it is called.
+func CountLoginsIn(store lockout.Store) {
+	accountLogins = lockout.New(store, AccountLockout)
+	ipLogins = lockout.New(store, IpLockout)
+}
+
+// checkLogin refuses the attempt while the account or the IP address has to wait, before the password is hashed.
+func checkLogin(accountKey string, ip string) error {
+	var wait time.Duration
+	checks := []struct {
+		limiter *lockout.Limiter
+		key     string
+	}{{accountLogins, accountKey}, {ipLogins, ipKey(ip)}}
+	for _, check := range checks {
+		w, err := check.limiter.Wait(check.key)
+		if err != nil {
+			logrus.WithError(err)
+			continue
+		}
+		if w > wait {
+			wait = w
+		}
+	}
+	if wait > 0 {
+		return failure.Throttled(fmt.Sprintf("too many failed sign in attempts, try again in %s", humanize(wait)), wait)
+	}
+	return nil
+}
+
+// failLogin counts the failed attempt. The owner of the account, if there is one, learns when it gets locked.
+func failLogin(actor *Actor, accountKey string, ip string) {
+	if _, err := ipLogins.Fail(ipKey(ip)); err != nil {
+		logrus.WithError(err)
+	}
+	locked, err := accountLogins.Fail(accountKey)
+	if err != nil {
+		logrus.WithError(err)
+		return
+	}
+	if locked && actor != nil {
+		if err := mailer.Lockout(actor.Email(), actor.Name(), humanize(AccountLockout.LockFor)); err != nil {
+			logrus.WithError(err).Error("the lockout email is not sent")
+		}
+	}
+}
+
+// succeedLogin forgets the failures of the account. The ones of the IP address are kept,
+// so that one right password does not clear the way for guessing others.
+func succeedLogin(accountKey string) {
+	if err := accountLogins.Succeed(accountKey); err != nil {
+		logrus.WithError(err)
+	}
+}
+
+func passwordKey(email string) string {
+	return "password:" + strings.ToLower(email)
+}
+
+func totpKey(actor *Actor) string {
+	return fmt.Sprintf("totp:%d", actor.AccountId())
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// humanize rounds the duration up to whole minutes, or seconds when it is shorter than a minute.
+func humanize(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d seconds", int(math.Ceil(d.Seconds())))
+	}
+	return fmt.Sprintf("%d minutes", int(math.Ceil(d.Minutes())))
+}
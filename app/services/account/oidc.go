@@ -0,0 +1,205 @@
+package account
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/oidc"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// OidcTTL is how long the user has to sign in at the identity provider.
+const OidcTTL = 10 * time.Minute
+
+// oidcPurpose marks the tokens that remember a sign in at the identity provider until the user comes back.
+const oidcPurpose = "oidc"
+
+var (
+	ErrOidcDisabled = failure.New(failure.NotFound, "signing in with an identity provider is not set up")
+	ErrOidcAttempt  = failure.New(failure.Unauthorized, "signing in took too long or was started elsewhere, try again")
+	ErrOidcFailed   = failure.New(failure.Unauthorized, "the identity provider did not sign you in, try again")
+	ErrOidcEmail    = failure.New(failure.Forbidden, "the identity provider has not confirmed your email")
+	ErrOidcRole     = failure.New(failure.Forbidden, "the identity provider gives you no role here, contact the administrator")
+)
+
+var identityProvider *oidc.Provider
+
+// SignInWith lets users sign in at the identity provider as well as with their password.
+func SignInWith(provider *oidc.Provider) {
+	identityProvider = provider
+}
+
+// OidcEnabled reports whether users can sign in at an identity provider.
+func OidcEnabled() bool {
+	return identityProvider != nil
+}
+
+// OidcStart is where to send the user to sign in, and the token remembering the attempt until they come back.
+type OidcStart struct {
+	Url       string
+	Attempt   string
+	ExpiresAt time.Time
+}
+
+// StartOidc begins signing in at the identity provider, which sends the user back to redirectUrl.
+// The attempt carries the state, the nonce and the PKCE verifier; it has to stay on the device, like a cookie.
+func StartOidc(redirectUrl string) (*OidcStart, error) {
+	if identityProvider == nil {
+		return nil, ErrOidcDisabled
+	}
+	secrets := make([]string, 3)
+	for i := range secrets {
+		secret, err := oidc.NewSecret()
+		if err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+	expiresAt := time.Now().Add(OidcTTL)
+	payload := jwt.MapClaims{
+		"purpose":  oidcPurpose,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      expiresAt.Unix(),
+	}
+	attempt, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString([]byte(initializers.Cfg.JwtSecretKey))
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return &OidcStart{
+		Url:       identityProvider.AuthCodeURL(redirectUrl, state, nonce, verifier),
+		Attempt:   attempt,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// FinishOidc exchanges the code the identity provider sent the user back with and finds the user it vouches for.
+// Someone new becomes a user with the roles the provider gives them; an existing user with the email is linked,
+// since the provider has confirmed it, and gets the roles they do not have yet. A user who never confirmed the email
+// may have been registered by someone else, so linking them drops their password and signs them out everywhere.
+func FinishOidc(attempt string, state string, code string, redirectUrl string) (*Actor, error) {
+	if identityProvider == nil {
+		return nil, ErrOidcDisabled
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(attempt, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(initializers.Cfg.JwtSecretKey), nil
+	})
+	if err != nil || claims["purpose"] != oidcPurpose {
+		return nil, ErrOidcAttempt
+	}
+	expected, _ := claims["state"].(string)
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(state)) != 1 {
+		return nil, ErrOidcAttempt
+	}
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	identity, err := identityProvider.Exchange(redirectUrl, code, verifier, nonce)
+	if err != nil {
+		logrus.WithError(err)
+		return nil, ErrOidcFailed
+	}
+	return oidcUser(identityProvider.Issuer(), identity)
+}
+
+// oidcUser finds the user the identity belongs to, links it to the user with its email or creates the user.
+func oidcUser(issuer string, identity *oidc.Claims) (*Actor, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOidcEmail
+	}
+	roles := rolesOf(identity)
+	var user *models.User
+	unconfirmed := false
+	if linked, err := repository.Identity.Get(issuer, identity.Subject); err == nil {
+		if user, err = repository.User.GetById(linked.UserId); err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+	} else {
+		user, err = repository.User.GetByEmail(identity.Email)
+		if err != nil {
+			if len(roles) == 0 {
+				return nil, ErrOidcRole
+			}
+			if user, err = newOidcUser(identity); err != nil {
+				return nil, err
+			}
+		}
+		unconfirmed = user.VerifiedAt == nil
+		err = repository.Identity.Create(&models.Identity{UserId: user.Id, Issuer: issuer, Subject: identity.Subject})
+		if err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+	}
+	actor := withRoles(user)
+	if unconfirmed {
+		if err := actor.setPassword(""); err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+	}
+	for _, role := range roles {
+		if actor.Has(role) {
+			continue
+		}
+		if err := actor.addRole(role); err != nil {
+			return nil, err
+		}
+	}
+	if !actor.Verified() && actor.Email() == identity.Email {
+		now := time.Now()
+		actor.User.VerifiedAt = &now
+		if err := repository.User.Update(actor.User); err != nil {
+			logrus.WithError(err)
+		}
+	}
+	if actor.Teacher == nil && actor.Student == nil {
+		return nil, ErrOidcRole
+	}
+	actor.Role = actor.defaultRole()
+	if actor.Deactivated() {
+		return nil, ErrDeactivated
+	}
+	return actor, nil
+}
+
+// newOidcUser creates the user the identity provider vouches for. They have no password until they reset one.
+func newOidcUser(identity *oidc.Claims) (*models.User, error) {
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+	now := time.Now()
+	user := &models.User{
+		Name:       name,
+		Email:      identity.Email,
+		VerifiedAt: &now,
+	}
+	if err := repository.User.Create(user); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return user, nil
+}
+
+// rolesOf maps the roles the identity provider gives the user to the ones they have here.
+func rolesOf(identity *oidc.Claims) []string {
+	roles := []string{}
+	for _, role := range identity.Roles {
+		switch role {
+		case initializers.Cfg.OidcTeacherRole:
+			roles = append(roles, Teacher)
+		case initializers.Cfg.OidcStudentRole:
+			roles = append(roles, Student)
+		}
+	}
+	return roles
+}
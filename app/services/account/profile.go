@@ -0,0 +1,126 @@
+package account
+
+import (
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrPassword = failure.New(failure.Invalid, "the current password is incorrect")
+
+// UpdateProfile changes the name and email of the user, in each of their roles. A new email needs the current password,
+// is mailed to the old address too and signs out the other sessions and the personal access tokens, so a hijacked session
+// cannot take the account over. It has to be confirmed again, link turns the token into the address the user follows.
+func UpdateProfile(actor *Actor, req forms.UpdateProfileRequest, link func(token string) string) error {
+	if actor.IsAdmin() {
+		return failure.ErrForbidden
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return failure.Validation(err)
+	}
+	oldEmail := actor.Email()
+	emailChanged := req.Email != oldEmail
+	if emailChanged {
+		if !utilities.CheckPasswordHash(req.CurrentPassword, actor.password()) {
+			return ErrPassword
+		}
+		if _, err := repository.User.GetByEmail(req.Email); err == nil {
+			return ErrConflict
+		}
+	}
+	actor.User.Name = req.Name
+	if emailChanged {
+		actor.User.Email = req.Email
+		actor.User.VerifiedAt = nil
+	}
+	if err := repository.User.Update(actor.User); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if err := actor.copyProfile(); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if emailChanged {
+		if err := mailer.EmailChanged(oldEmail, actor.Name(), req.Email); err != nil {
+			logrus.WithError(err).Error("the old email is not told about the change")
+		}
+		if err := actor.revokeApiTokens(); err != nil {
+			return failure.ErrSomethingWrong
+		}
+		if err := SignOutElsewhere(actor); err != nil {
+			return err
+		}
+		if err := sendVerification(actor, link); err != nil {
+			logrus.WithError(err)
+		}
+	}
+	return nil
+}
+
+// copyProfile repeats the name and email of the user in their roles.
+func (a *Actor) copyProfile() error {
+	if a.Teacher != nil {
+		a.Teacher.Name = a.User.Name
+		a.Teacher.Email = a.User.Email
+		if err := repository.Teacher.Update(a.Teacher); err != nil {
+			return err
+		}
+	}
+	if a.Student != nil {
+		a.Student.Name = a.User.Name
+		a.Student.Email = a.User.Email
+		if err := repository.Student.Update(a.Student); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChangePassword sets the new password once the current one is confirmed. Every session is signed out,
+// so the caller has to start a new one for the device the password was changed on.
+func ChangePassword(actor *Actor, req forms.ChangePasswordRequest) error {
+	if actor.IsAdmin() {
+		return failure.ErrForbidden
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return failure.Validation(err)
+	}
+	if !utilities.CheckPasswordHash(req.CurrentPassword, actor.password()) {
+		return ErrPassword
+	}
+	password, err := utilities.HashPassword(req.Password)
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if err := actor.setPassword(password); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// setPassword saves the hashed password of the account, signs out every session and revokes the personal access tokens.
+func (a *Actor) setPassword(hash string) error {
+	now := time.Now()
+	var err error
+	if a.IsAdmin() {
+		a.Admin.Password = hash
+		a.Admin.PasswordChangedAt = &now
+		err = repository.Admin.Update(a.Admin)
+	} else {
+		a.User.Password = hash
+		a.User.PasswordChangedAt = &now
+		err = repository.User.Update(a.User)
+	}
+	if err != nil {
+		return err
+	}
+	if err := a.revokeApiTokens(); err != nil {
+		return err
+	}
+	return SignOutEverywhere(a)
+}
@@ -0,0 +1,130 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/application/utilities"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ResetTTL is how long a reset link works.
+	ResetTTL = time.Hour
+	// ResetLimit is how many resets can be requested for an email within ResetWindow.
+	ResetLimit  = 3
+	ResetWindow = time.Hour
+)
+
+// resetRole marks the resets of user passwords; resets of admin passwords are marked with their role.
+const resetRole = "user"
+
+var (
+	ErrResetToken    = failure.New(failure.Invalid, "the reset link is invalid or has expired, ask for a new one")
+	ErrTooManyResets = failure.New(failure.TooManyRequests, "too many password reset requests, try again later")
+)
+
+// RequestReset mails a one-time reset link to the account. link turns the token into the address the user follows.
+// The answer is the same whether the account exists or not, so that nobody can find out who has one.
+func RequestReset(req forms.PasswordResetRequest, link func(token string) string) error {
+	if err := initializers.Validator.Struct(req); err != nil {
+		return failure.Validation(err)
+	}
+	count, err := repository.PasswordReset.CountSince(req.Email, time.Now().Add(-ResetWindow))
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if count >= ResetLimit {
+		return ErrTooManyResets
+	}
+	token, err := newSecret()
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	actors := find(req.Email)
+	// the request is recorded for unknown accounts too, so they are limited the same way
+	reset := &models.PasswordReset{
+		Email:     req.Email,
+		Role:      resetRole,
+		TokenHash: hashSecret(token),
+		ExpiresAt: time.Now().Add(ResetTTL),
+	}
+	if len(actors) > 0 && actors[0].IsAdmin() {
+		reset.Role = Admin
+	}
+	if err := repository.PasswordReset.Create(reset); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if len(actors) == 0 {
+		return nil
+	}
+	actor := actors[0]
+	err = mailer.PasswordReset(actor.Email(), actor.Name(), link(token), fmt.Sprintf("%d minutes", int(ResetTTL.Minutes())))
+	if err != nil {
+		// the answer stays the same as for an unknown email, so that it does not tell which ones have an account
+		logrus.WithError(err).Error("the password reset email is not sent")
+	}
+	return nil
+}
+
+// ResetPassword sets the new password of the account the token was mailed to. The token and every other
+// unused token of the account stop working.
+func ResetPassword(token string, req forms.ResetPasswordRequest) error {
+	if err := initializers.Validator.Struct(req); err != nil {
+		return failure.Validation(err)
+	}
+	reset, err := repository.PasswordReset.GetByTokenHash(hashSecret(token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return ErrResetToken
+	}
+	actor := resetAccount(reset)
+	if actor == nil {
+		return ErrResetToken
+	}
+	password, err := utilities.HashPassword(req.Password)
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if err := repository.PasswordReset.Use(reset); err != nil {
+		return ErrResetToken
+	}
+	if err := actor.setPassword(password); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// resetAccount returns the user or admin whose password the reset is for. Resets made before users could have
+// several roles are marked with the role and reset the user's password.
+func resetAccount(reset *models.PasswordReset) *Actor {
+	for _, actor := range find(reset.Email) {
+		if actor.IsAdmin() == (reset.Role == Admin) {
+			return actor
+		}
+	}
+	return nil
+}
+
+// newSecret returns a random token that is safe to put into a link or a cookie.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSecret(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package account
+
+import (
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+)
+
+var (
+	ErrRoleAdded   = failure.New(failure.Conflict, "you already have this role")
+	ErrRoleMissing = failure.New(failure.Invalid, "add the role on your profile first")
+)
+
+// Has reports whether the user has the role, deactivated or not.
+func (a *Actor) Has(role string) bool {
+	switch role {
+	case Teacher:
+		return a.Teacher != nil
+	case Student:
+		return a.Student != nil
+	}
+	return false
+}
+
+// Roles returns the roles the user can act in, teaching first. Admins act as admins only.
+func (a *Actor) Roles() []string {
+	if a.IsAdmin() {
+		return []string{Admin}
+	}
+	roles := []string{}
+	if a.Teacher != nil && a.Teacher.DeactivatedAt == nil {
+		roles = append(roles, Teacher)
+	}
+	if a.Student != nil && a.Student.DeactivatedAt == nil {
+		roles = append(roles, Student)
+	}
+	return roles
+}
+
+// defaultRole is the role the user acts in after signing in, none when every role is deactivated.
+func (a *Actor) defaultRole() string {
+	if roles := a.Roles(); len(roles) > 0 {
+		return roles[0]
+	}
+	return ""
+}
+
+// sessionRoles are the roles the sessions of the account can act in.
+func (a *Actor) sessionRoles() []string {
+	if a.IsAdmin() {
+		return []string{Admin}
+	}
+	return []string{Teacher, Student}
+}
+
+// AddRole lets the user teach as well as study, or the other way round. They keep acting in the current role until they switch.
+func AddRole(actor *Actor, req forms.RoleRequest) error {
+	if actor.IsAdmin() {
+		return failure.ErrForbidden
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return failure.Validation(err)
+	}
+	if actor.Has(req.Role) {
+		return ErrRoleAdded
+	}
+	return actor.addRole(req.Role)
+}
+
+// SwitchRole makes the device act in another role of the user. The session gets new tokens for it.
+func SwitchRole(actor *Actor, req forms.RoleRequest, device Device) (*Tokens, error) {
+	if actor.IsAdmin() {
+		return nil, failure.ErrForbidden
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	if !actor.Has(req.Role) {
+		return nil, ErrRoleMissing
+	}
+	previous := actor.Role
+	actor.Role = req.Role
+	if actor.Deactivated() {
+		actor.Role = previous
+		return nil, ErrDeactivated
+	}
+	session, err := repository.Session.GetById(actor.SessionId)
+	now := time.Now()
+	if err != nil || !session.Active(now) {
+		return nil, ErrSession
+	}
+	refresh, err := newSecret()
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	hash := session.RefreshHash
+	session.Role = req.Role
+	session.RefreshHash = hashSecret(refresh)
+	session.UserAgent = device.UserAgent
+	session.Ip = device.Ip
+	session.ExpiresAt = now.Add(SessionTTL)
+	session.LastUsedAt = now
+	if err := repository.Session.Rotate(session, hash); err != nil {
+		return nil, ErrSession
+	}
+	return issueTokens(actor, session, refresh)
+}
+
+// addRole creates the role with the name and email of the user.
+func (a *Actor) addRole(role string) error {
+	var err error
+	switch role {
+	case Teacher:
+		teacher := &models.Teacher{UserId: a.User.Id, Name: a.User.Name, Email: a.User.Email}
+		if err = repository.Teacher.Create(teacher); err == nil {
+			a.Teacher = teacher
+		}
+	case Student:
+		student := &models.Student{UserId: a.User.Id, Name: a.User.Name, Email: a.User.Email}
+		if err = repository.Student.Create(student); err == nil {
+			a.Student = student
+		}
+	}
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package account
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// AccessTTL is how long an access token works; the refresh token renews it while the session lasts.
+	AccessTTL = 15 * time.Minute
+	// SessionTTL is how long a session lasts without being used.
+	SessionTTL = 30 * 24 * time.Hour
+)
+
+var ErrSession = failure.New(failure.Unauthorized, "your session has ended, sign in again")
+
+// Device is where the session was started or last used from.
+type Device struct {
+	UserAgent string
+	Ip        string
+}
+
+// Tokens sign the device in: the access token authorizes requests, the refresh token renews it.
+type Tokens struct {
+	Access           string
+	AccessExpiresAt  time.Time
+	Refresh          string
+	RefreshExpiresAt time.Time
+}
+
+// StartSession signs the actor in on the device.
+func StartSession(actor *Actor, device Device) (*Tokens, error) {
+	refresh, err := newSecret()
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	now := time.Now()
+	session := &models.Session{
+		AccountId:   actor.AccountId(),
+		Role:        actor.Role,
+		RefreshHash: hashSecret(refresh),
+		UserAgent:   device.UserAgent,
+		Ip:          device.Ip,
+		ExpiresAt:   now.Add(SessionTTL),
+		LastUsedAt:  now,
+	}
+	if err := repository.Session.Create(session); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	actor.SessionId = session.Id
+	return issueTokens(actor, session, refresh)
+}
+
+// Refresh renews the tokens of the session the refresh token belongs to and rotates the refresh token.
+// A refresh token that has been rotated away already was copied, so the session it belonged to is revoked.
+func Refresh(refresh string, device Device) (*Actor, *Tokens, error) {
+	hash := hashSecret(refresh)
+	session, err := repository.Session.GetByRefreshHash(hash)
+	if err != nil {
+		if reused, err := repository.Session.GetByPreviousHash(hash); err == nil && reused.RevokedAt == nil {
+			if err := repository.Session.Revoke(reused); err != nil {
+				logrus.WithError(err)
+			}
+		}
+		return nil, nil, ErrSession
+	}
+	now := time.Now()
+	if !session.Active(now) {
+		return nil, nil, ErrSession
+	}
+	actor, err := Resolve(session.AccountId, session.Role, now)
+	if err != nil {
+		return nil, nil, ErrSession
+	}
+	newRefresh, err := newSecret()
+	if err != nil {
+		return nil, nil, failure.ErrSomethingWrong
+	}
+	session.RefreshHash = hashSecret(newRefresh)
+	session.UserAgent = device.UserAgent
+	session.Ip = device.Ip
+	session.ExpiresAt = now.Add(SessionTTL)
+	session.LastUsedAt = now
+	if err := repository.Session.Rotate(session, hash); err != nil {
+		return nil, nil, ErrSession
+	}
+	actor.SessionId = session.Id
+	tokens, err := issueTokens(actor, session, newRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+	return actor, tokens, nil
+}
+
+// CheckSession makes sure the session the access token was issued for has not been revoked.
+func CheckSession(claims jwt.MapClaims) error {
+	sessionId, ok := claims["sid"].(float64)
+	if !ok {
+		return ErrSession
+	}
+	session, err := repository.Session.GetById(uint(sessionId))
+	if err != nil || !session.Active(time.Now()) {
+		return ErrSession
+	}
+	return nil
+}
+
+// SignOut revokes the session the refresh token belongs to.
+func SignOut(refresh string) error {
+	session, err := repository.Session.GetByRefreshHash(hashSecret(refresh))
+	if err != nil {
+		return nil
+	}
+	if err := repository.Session.Revoke(session); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// Sessions returns the devices the actor is signed in on in any role, the latest used first.
+func Sessions(actor *Actor) ([]models.Session, error) {
+	sessions, err := repository.Session.GetActive(actor.sessionRoles(), actor.AccountId())
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *sessions, nil
+}
+
+// RevokeSession signs the actor out on one of their devices.
+func RevokeSession(actor *Actor, id uint) error {
+	session, err := repository.Session.GetById(id)
+	if err != nil {
+		return failure.ErrNotFound
+	}
+	if err := policy.Session(actor, policy.Delete, session); err != nil {
+		return err
+	}
+	if err := repository.Session.Revoke(session); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// SignOutEverywhere revokes every session of the account.
+func SignOutEverywhere(actor *Actor) error {
+	if err := repository.Session.RevokeAll(actor.sessionRoles(), actor.AccountId()); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// SignOutElsewhere revokes every session of the account but the one the actor signed in with.
+func SignOutElsewhere(actor *Actor) error {
+	if err := repository.Session.RevokeAllBut(actor.sessionRoles(), actor.AccountId(), actor.SessionId); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// SignOutOfRole revokes the sessions of the user that act in the role. Their other role stays signed in.
+func SignOutOfRole(userId uint, role string) error {
+	if err := repository.Session.RevokeAll([]string{role}, userId); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// issueTokens signs a new access token for the session and pairs it with the refresh token.
+func issueTokens(actor *Actor, session *models.Session, refresh string) (*Tokens, error) {
+	expiresAt := time.Now().Add(AccessTTL)
+	payload := jwt.MapClaims{
+		"sub":   strconv.FormatUint(uint64(actor.AccountId()), 10),
+		"roles": actor.Role,
+		"sid":   session.Id,
+		"iat":   time.Now().Unix(),
+		"exp":   expiresAt.Unix(),
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString([]byte(initializers.Cfg.JwtSecretKey))
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return &Tokens{
+		Access:           access,
+		AccessExpiresAt:  expiresAt,
+		Refresh:          refresh,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
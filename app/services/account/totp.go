@@ -0,0 +1,290 @@
+package account
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/MikhailR1337/task-sync-x/app/services/totp"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ChallengeTTL is how long the second step of signing in waits for the code.
+	ChallengeTTL = 5 * time.Minute
+	// RecoveryCodes is how many recovery codes a teacher gets at a time.
+	RecoveryCodes = 10
+	// TotpIssuer names the app in authenticator apps.
+	TotpIssuer = "Task Sync"
+)
+
+// TeacherTotp is the setting that makes every teacher sign in with two factors, and its values.
+const (
+	TeacherTotp  = "teacher_totp"
+	TotpRequired = "required"
+	TotpOptional = "optional"
+)
+
+// totpPurpose marks the tokens of the second sign in step, so they cannot be used to sign in on their own.
+const totpPurpose = "totp"
+
+var (
+	ErrTotpCode      = failure.New(failure.Invalid, "the code is incorrect or has been used already")
+	ErrChallenge     = failure.New(failure.Unauthorized, "signing in took too long, enter your password again")
+	ErrTotpEnabled   = failure.New(failure.Conflict, "two-factor authentication is already on")
+	ErrTotpDisabled  = failure.New(failure.Conflict, "two-factor authentication is off")
+	ErrTotpSetup     = failure.New(failure.Conflict, "start setting up two-factor authentication first")
+	ErrTotpRequired  = failure.New(failure.Forbidden, "set up two-factor authentication on your profile to continue")
+	ErrTotpMandatory = failure.New(failure.Forbidden, "two-factor authentication is required for teachers")
+)
+
+// clock is the time codes are checked at.
+var clock = time.Now
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TotpSetup is what the authenticator app needs to show the codes, as a QR code of the URI or the secret typed in.
+type TotpSetup struct {
+	Secret string
+	Uri    string
+}
+
+// TotpEnabled reports whether the user signs in with a code from the authenticator app as well as the password.
+// Teachers set it up, and it guards the sign in whatever role the user goes on to act in.
+func (a *Actor) TotpEnabled() bool {
+	return a.Teacher != nil && a.Teacher.TotpEnabledAt != nil
+}
+
+// TeacherTotpRequired reports whether admins have made two-factor authentication mandatory for teachers.
+func TeacherTotpRequired() bool {
+	setting, err := repository.Setting.Get(TeacherTotp)
+	if err != nil {
+		return false
+	}
+	return setting.Value == TotpRequired
+}
+
+// CheckTotp keeps teachers who have to set up two-factor authentication on their profile until they do.
+func CheckTotp(actor *Actor) error {
+	if actor.IsTeacher() && !actor.TotpEnabled() && TeacherTotpRequired() {
+		return ErrTotpRequired
+	}
+	return nil
+}
+
+// Challenge returns the token that carries the actor who entered the right password to the second sign in step.
+func Challenge(actor *Actor) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ChallengeTTL)
+	payload := jwt.MapClaims{
+		"sub":     strconv.FormatUint(uint64(actor.AccountId()), 10),
+		"roles":   actor.Role,
+		"purpose": totpPurpose,
+		"iat":     time.Now().Unix(),
+		"exp":     expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString([]byte(initializers.Cfg.JwtSecretKey))
+	if err != nil {
+		return "", time.Time{}, failure.ErrSomethingWrong
+	}
+	return token, expiresAt, nil
+}
+
+// CompleteLogin checks the code of the teacher the challenge was issued for, who can then be signed in.
+// Wrong codes count like wrong passwords.
+func CompleteLogin(req forms.TotpLoginRequest, ip string) (*Actor, error) {
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(req.Challenge, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(initializers.Cfg.JwtSecretKey), nil
+	})
+	if err != nil || claims["purpose"] != totpPurpose {
+		return nil, ErrChallenge
+	}
+	sub, _ := claims["sub"].(string)
+	id, err := strconv.ParseUint(sub, 10, 32)
+	if err != nil {
+		return nil, ErrChallenge
+	}
+	role, _ := claims["roles"].(string)
+	issuedAt, _ := claims["iat"].(float64)
+	actor, err := Resolve(uint(id), role, time.Unix(int64(issuedAt), 0))
+	if err != nil || !actor.TotpEnabled() {
+		return nil, ErrChallenge
+	}
+	key := totpKey(actor)
+	if err := checkLogin(key, ip); err != nil {
+		return nil, err
+	}
+	if err := checkCode(actor, req.Code); err != nil {
+		failLogin(actor, key, ip)
+		return nil, err
+	}
+	succeedLogin(key)
+	return actor, nil
+}
+
+// StartTotp gives the teacher a new secret for their authenticator app. It is used once they confirm a code with EnableTotp.
+func StartTotp(actor *Actor) (*TotpSetup, error) {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
+	}
+	if actor.TotpEnabled() {
+		return nil, ErrTotpEnabled
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	actor.Teacher.TotpSecret = secret
+	if err := repository.Teacher.Update(actor.Teacher); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return &TotpSetup{Secret: secret, Uri: totp.URI(TotpIssuer, actor.Email(), secret)}, nil
+}
+
+// PendingTotp returns the setup StartTotp gave the teacher while it waits for a code to confirm it, an empty one otherwise.
+func PendingTotp(actor *Actor) *TotpSetup {
+	if !actor.IsTeacher() || actor.TotpEnabled() || actor.Teacher.TotpSecret == "" {
+		return &TotpSetup{}
+	}
+	return &TotpSetup{Secret: actor.Teacher.TotpSecret, Uri: totp.URI(TotpIssuer, actor.Email(), actor.Teacher.TotpSecret)}
+}
+
+// EnableTotp turns two-factor authentication on once the code shows the authenticator app has the secret,
+// and returns the recovery codes. They are shown this once.
+func EnableTotp(actor *Actor, req forms.TotpCodeRequest) ([]string, error) {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	if actor.TotpEnabled() {
+		return nil, ErrTotpEnabled
+	}
+	if actor.Teacher.TotpSecret == "" {
+		return nil, ErrTotpSetup
+	}
+	step, ok := totp.Validate(actor.Teacher.TotpSecret, normalizeCode(req.Code), clock())
+	if !ok {
+		return nil, ErrTotpCode
+	}
+	now := time.Now()
+	actor.Teacher.TotpEnabledAt = &now
+	actor.Teacher.TotpLastStep = step
+	if err := repository.Teacher.Update(actor.Teacher); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return newRecoveryCodes(actor.Teacher)
+}
+
+// NewRecoveryCodes replaces the teacher's recovery codes once they prove they have a second factor.
+func NewRecoveryCodes(actor *Actor, req forms.TotpCodeRequest) ([]string, error) {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	if !actor.TotpEnabled() {
+		return nil, ErrTotpDisabled
+	}
+	if err := checkCode(actor, req.Code); err != nil {
+		return nil, err
+	}
+	return newRecoveryCodes(actor.Teacher)
+}
+
+// DisableTotp turns two-factor authentication off unless admins require it.
+func DisableTotp(actor *Actor, req forms.TotpCodeRequest) error {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return failure.Validation(err)
+	}
+	if !actor.TotpEnabled() {
+		return ErrTotpDisabled
+	}
+	if TeacherTotpRequired() {
+		return ErrTotpMandatory
+	}
+	if err := checkCode(actor, req.Code); err != nil {
+		return err
+	}
+	actor.Teacher.TotpSecret = ""
+	actor.Teacher.TotpEnabledAt = nil
+	if err := repository.Teacher.Update(actor.Teacher); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if err := repository.RecoveryCode.DeleteByTeacherId(actor.Teacher.Id); err != nil {
+		logrus.WithError(err)
+	}
+	return nil
+}
+
+// RecoveryCodesLeft returns how many recovery codes the teacher has not used.
+func RecoveryCodesLeft(actor *Actor) int64 {
+	if !actor.TotpEnabled() {
+		return 0
+	}
+	count, err := repository.RecoveryCode.CountUnused(actor.Teacher.Id)
+	if err != nil {
+		logrus.WithError(err)
+	}
+	return count
+}
+
+// checkCode accepts a code of the authenticator app that has not been used yet, or an unused recovery code.
+func checkCode(actor *Actor, code string) error {
+	code = normalizeCode(code)
+	if step, ok := totp.Validate(actor.Teacher.TotpSecret, code, clock()); ok {
+		if err := repository.Teacher.UseTotpStep(actor.Teacher, step); err != nil {
+			return ErrTotpCode
+		}
+		return nil
+	}
+	if err := repository.RecoveryCode.Use(actor.Teacher.Id, hashSecret(code)); err != nil {
+		return ErrTotpCode
+	}
+	return nil
+}
+
+// newRecoveryCodes gives the teacher new recovery codes and returns them; only their hashes are kept.
+func newRecoveryCodes(teacher *models.Teacher) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodes)
+	hashed := make([]models.RecoveryCode, 0, RecoveryCodes)
+	for i := 0; i < RecoveryCodes; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(b))
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashed = append(hashed, models.RecoveryCode{TeacherId: teacher.Id, CodeHash: hashSecret(code)})
+	}
+	if err := repository.RecoveryCode.Replace(teacher.Id, &hashed); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return codes, nil
+}
+
+// normalizeCode drops the spaces and dashes people type or copy along with a code.
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
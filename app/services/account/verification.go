@@ -0,0 +1,81 @@
+package account
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// VerificationTTL is how long the link confirming an email works.
+const VerificationTTL = 48 * time.Hour
+
+// verifyPurpose marks the tokens that confirm an email, so they cannot be used to sign in and the other way round.
+const verifyPurpose = "verify"
+
+var (
+	ErrUnverified  = failure.New(failure.Forbidden, "confirm your email to continue, we have sent you a link")
+	ErrVerified    = failure.New(failure.Conflict, "your email is already confirmed")
+	ErrVerifyToken = failure.New(failure.Invalid, "the confirmation link is invalid or has expired, ask for a new one")
+)
+
+// ResendVerification mails the link confirming the email of the signed in account again.
+func ResendVerification(actor *Actor, link func(token string) string) error {
+	if actor.Verified() {
+		return ErrVerified
+	}
+	return sendVerification(actor, link)
+}
+
+// Verify confirms the email of the user the token was mailed to.
+func Verify(token string) (*Actor, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(initializers.Cfg.JwtSecretKey), nil
+	})
+	if err != nil || claims["purpose"] != verifyPurpose {
+		return nil, ErrVerifyToken
+	}
+	email, _ := claims["sub"].(string)
+	user, err := repository.User.GetByEmail(email)
+	if err != nil {
+		return nil, ErrVerifyToken
+	}
+	actor := withRoles(user)
+	if actor.Verified() {
+		return actor, nil
+	}
+	now := time.Now()
+	actor.User.VerifiedAt = &now
+	if err := repository.User.Update(actor.User); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return actor, nil
+}
+
+// sendVerification mails the link with a signed token confirming the email of the account.
+func sendVerification(actor *Actor, link func(token string) string) error {
+	payload := jwt.MapClaims{
+		"sub":     actor.Email(),
+		"purpose": verifyPurpose,
+		"exp":     time.Now().Add(VerificationTTL).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString([]byte(initializers.Cfg.JwtSecretKey))
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	err = mailer.Verification(actor.Email(), actor.Name(), link(token), fmt.Sprintf("%d hours", int(VerificationTTL.Hours())))
+	if err != nil {
+		logrus.WithError(err).Error("the verification email is not sent")
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
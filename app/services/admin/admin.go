@@ -0,0 +1,291 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/homeworks"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/sirupsen/logrus"
+)
+
+// Actions recorded in the audit trail.
+const (
+	Deactivate   = "deactivate"
+	Reactivate   = "reactivate"
+	Reassign     = "reassign"
+	ViewStudent  = "view student"
+	ViewHomework = "view homework"
+	ViewFile     = "view file"
+	SetTotp      = "set two-factor authentication"
+)
+
+// AuditLimit is how many of the latest audit entries the console shows.
+const AuditLimit = 200
+
+const deactivated = "deactivated"
+
+var ErrSameTeacher = failure.New(failure.Conflict, "the student already studies with this teacher")
+
+// Users are the accounts matching a search.
+type Users struct {
+	Teachers []models.Teacher
+	Students []models.Student
+}
+
+// StudentDetail is everything the console shows about a student.
+type StudentDetail struct {
+	Student     *models.Student
+	Enrollments []models.Enrollment
+	Homeworks   []models.Homework
+	Teachers    []models.Teacher
+}
+
+// Search returns the teachers and students whose name or email contains the query.
+func Search(actor *account.Actor, query string) (*Users, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	query = strings.TrimSpace(query)
+	teachers, err := repository.Teacher.Search(query)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	students, err := repository.Student.Search(query)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return &Users{Teachers: *teachers, Students: *students}, nil
+}
+
+// SetTeacherStatus deactivates the teacher, whose sessions acting as a teacher end at once, or lets them teach again.
+func SetTeacherStatus(actor *account.Actor, id uint, req forms.AccountStatusRequest) (*models.Teacher, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	teacher, err := repository.Teacher.GetById(id)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	action := setStatus(&teacher.DeactivatedAt, req.Status)
+	if err := repository.Audit.Save(entry(actor, action, account.Teacher, teacher.Id, teacher.Email), teacher); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	if teacher.DeactivatedAt != nil {
+		if err := account.SignOutOfRole(teacher.UserId, account.Teacher); err != nil {
+			logrus.WithError(err).Error("the deactivated teacher is not signed out")
+		}
+	}
+	return teacher, nil
+}
+
+// SetStudentStatus deactivates the student, whose sessions acting as a student end at once, or lets them study again.
+func SetStudentStatus(actor *account.Actor, id uint, req forms.AccountStatusRequest) (*models.Student, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	student, err := repository.Student.GetById(id)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	action := setStatus(&student.DeactivatedAt, req.Status)
+	if err := repository.Audit.Save(entry(actor, action, account.Student, student.Id, student.Email), student); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	if student.DeactivatedAt != nil {
+		if err := account.SignOutOfRole(student.UserId, account.Student); err != nil {
+			logrus.WithError(err).Error("the deactivated student is not signed out")
+		}
+	}
+	return student, nil
+}
+
+// SetTeacherTotp makes two-factor authentication mandatory for every teacher or leaves it to them.
+// Teachers without it can only set it up on their profile while it is mandatory.
+// Teachers cannot change it themselves: anyone can register as one, and it binds every teacher, so it stays with
+// admins, whose changes go to the audit trail.
+func SetTeacherTotp(actor *account.Actor, req forms.TotpPolicyRequest) error {
+	if err := policy.AdminOnly(actor); err != nil {
+		return err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return failure.Validation(err)
+	}
+	setting := &models.Setting{Name: account.TeacherTotp, Value: req.Teachers}
+	if err := repository.Audit.Save(entry(actor, SetTotp, "setting", 0, fmt.Sprintf("%s for teachers", req.Teachers)), setting); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// Student returns the student with their teachers and homework.
+func Student(actor *account.Actor, id uint) (*StudentDetail, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	student, err := repository.Student.GetById(id)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	if err := policy.Student(actor, policy.View, student); err != nil {
+		return nil, err
+	}
+	enrollments, err := repository.Enrollment.GetByStudentId(student.Id)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	list, err := repository.Homework.GetByStudentId(student.Id, repository.HomeworkFilter{})
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	teachers, err := repository.Teacher.GetList()
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	if err := record(actor, ViewStudent, account.Student, student.Id, student.Email); err != nil {
+		return nil, err
+	}
+	return &StudentDetail{
+		Student:     student,
+		Enrollments: *enrollments,
+		Homeworks:   *list,
+		Teachers:    *teachers,
+	}, nil
+}
+
+// ReassignStudent moves the enrolled student to another teacher. Their studies with the old teacher finish,
+// the homework they got stays with the old teacher.
+func ReassignStudent(actor *account.Actor, enrollmentId uint, req forms.ReassignRequest) (*models.Enrollment, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	enrollment, err := repository.Enrollment.GetById(enrollmentId)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	if err := policy.Enrollment(actor, policy.Update, enrollment); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	teacherId, err := strconv.ParseUint(req.Teacher, 10, 32)
+	if err != nil {
+		return nil, account.ErrTeacher
+	}
+	teacher, err := repository.Teacher.GetById(uint(teacherId))
+	if err != nil || teacher.DeactivatedAt != nil {
+		return nil, account.ErrTeacher
+	}
+	if teacher.Id == enrollment.TeacherId {
+		return nil, ErrSameTeacher
+	}
+	now := time.Now()
+	if enrollment.Active() {
+		enrollment.Status = models.EnrollmentFinished
+		enrollment.EndsAt = &now
+	}
+	next := &models.Enrollment{
+		StudentId: enrollment.StudentId,
+		TeacherId: teacher.Id,
+		Status:    models.EnrollmentActive,
+		StartsAt:  now,
+	}
+	reassigned := entry(actor, Reassign, account.Student, enrollment.StudentId,
+		fmt.Sprintf("from %s to %s", enrollment.Teacher.Email, teacher.Email))
+	if err := repository.Enrollment.Reassign(enrollment, next, reassigned); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return enrollment, nil
+}
+
+// Homework returns any homework for the admin to look at.
+func Homework(actor *account.Actor, id uint) (*homeworks.Detail, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	detail, err := homeworks.Get(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := record(actor, ViewHomework, "homework", detail.Homework.Id, detail.Homework.Name); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// File returns a file submitted for any homework with its content.
+func File(actor *account.Actor, homeworkId uint, id uint) (*models.SubmissionFile, io.ReadCloser, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, nil, err
+	}
+	file, content, err := homeworks.File(actor, homeworkId, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := record(actor, ViewFile, "homework", homeworkId, file.Name); err != nil {
+		content.Close()
+		return nil, nil, err
+	}
+	return file, content, nil
+}
+
+// Audit returns the latest admin actions, newest first.
+func Audit(actor *account.Actor) ([]models.AuditEntry, error) {
+	if err := policy.AdminOnly(actor); err != nil {
+		return nil, err
+	}
+	entries, err := repository.Audit.GetLatest(AuditLimit)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *entries, nil
+}
+
+// setStatus deactivates or reactivates the account and returns the action to record.
+func setStatus(deactivatedAt **time.Time, status string) string {
+	if status == deactivated {
+		if *deactivatedAt == nil {
+			now := time.Now()
+			*deactivatedAt = &now
+		}
+		return Deactivate
+	}
+	*deactivatedAt = nil
+	return Reactivate
+}
+
+// entry is the audit trail entry of the action, saved together with the change it records.
+func entry(actor *account.Actor, action string, subject string, subjectId uint, details string) *models.AuditEntry {
+	return &models.AuditEntry{
+		AdminId:   actor.Id(),
+		Action:    action,
+		Subject:   subject,
+		SubjectId: subjectId,
+		Details:   details,
+	}
+}
+
+// record adds the action that changes nothing to the audit trail. The admin does not get to see what is not recorded.
+func record(actor *account.Actor, action string, subject string, subjectId uint, details string) error {
+	if err := repository.Audit.Create(entry(actor, action, subject, subjectId, details)); err != nil {
+		logrus.WithError(err).Error("the admin action is not recorded")
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package deadline
+
+import (
+	"math"
+	"time"
+)
+
+type Status string
+
+const (
+	None    Status = ""
+	OnTime  Status = "on-time"
+	Late    Status = "late"
+	Overdue Status = "overdue"
+)
+
+// InputLayout is the layout of datetime-local inputs in the forms.
+const InputLayout = "2006-01-02T15:04"
+
+const day = 24 * time.Hour
+
+// Check reports whether homework was (or still can be) submitted in time.
+// Homework without a due date has no deadline status.
+func Check(dueAt *time.Time, submittedAt *time.Time, now time.Time) Status {
+	if dueAt == nil {
+		return None
+	}
+	if submittedAt != nil {
+		if submittedAt.After(*dueAt) {
+			return Late
+		}
+		return OnTime
+	}
+	if now.After(*dueAt) {
+		return Overdue
+	}
+	return OnTime
+}
+
+// DaysLate returns the number of started days between the due date and the submission.
+func DaysLate(dueAt *time.Time, submittedAt *time.Time) int {
+	if dueAt == nil || submittedAt == nil || !submittedAt.After(*dueAt) {
+		return 0
+	}
+	return int(math.Ceil(float64(submittedAt.Sub(*dueAt)) / float64(day)))
+}
+
+// Penalize reduces points by percent for every started day the submission is late.
+func Penalize(points float64, percent uint8, dueAt *time.Time, submittedAt *time.Time) float64 {
+	total := DaysLate(dueAt, submittedAt) * int(percent)
+	if total <= 0 {
+		return points
+	}
+	if total >= 100 {
+		return 0
+	}
+	return math.Round(points*float64(100-total)) / 100
+}
@@ -0,0 +1,97 @@
+package failure
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Kind tells the transport which kind of problem the operation failed with.
+type Kind int
+
+const (
+	Internal Kind = iota
+	Unauthorized
+	Forbidden
+	NotFound
+	Conflict
+	Invalid
+	TooManyRequests
+)
+
+var (
+	ErrSomethingWrong = New(Internal, "something wrong. try again")
+	ErrForbidden      = New(Forbidden, "you do not have access to this page")
+	ErrNotFound       = New(NotFound, "404 Oops, page not found")
+	ErrValidation     = New(Invalid, "something wrong with your data. change something and try again")
+)
+
+// Error is an error of a known kind. Fields holds the failed validation rule of every invalid field,
+// RetryAfter how long to wait before trying again.
+type Error struct {
+	Kind       Kind
+	Err        error
+	Fields     map[string]string
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Err: errors.New(message)}
+}
+
+func Wrap(kind Kind, err error) error {
+	return &Error{Kind: kind, Err: err}
+}
+
+// Throttled is a TooManyRequests error telling when to try again.
+func Throttled(message string, retryAfter time.Duration) error {
+	return &Error{Kind: TooManyRequests, Err: errors.New(message), RetryAfter: retryAfter}
+}
+
+// Validation turns validator errors into an Invalid error listing the failed fields.
+func Validation(err error) error {
+	fields := map[string]string{}
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			fields[fieldError.Field()] = fieldError.Tag()
+		}
+	}
+	return &Error{Kind: Invalid, Err: ErrValidation, Fields: fields}
+}
+
+// KindOf returns the kind of err, Internal when it is not known.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Internal
+}
+
+// FieldsOf returns the invalid fields of err, if any.
+func FieldsOf(err error) map[string]string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
+
+// RetryAfterOf returns how long to wait before trying again, zero when err does not say.
+func RetryAfterOf(err error) time.Duration {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package grading
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Limit is the largest score the points columns can hold.
+const Limit = 99999.99
+
+var (
+	ErrPoints     = errors.New("points should be a positive number with at most two decimals")
+	ErrExceedsMax = errors.New("points cannot be greater than the maximum points")
+	ErrTotal      = fmt.Errorf("the points cannot add up to more than %.2f", Limit)
+)
+
+// Parse reads points written with a dot or a comma as the decimal separator.
+func Parse(s string) (float64, error) {
+	s = strings.Replace(strings.TrimSpace(s), ",", ".", 1)
+	points, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(points) || points < 0 || points > Limit {
+		return 0, ErrPoints
+	}
+	if math.Abs(points*100-math.Round(points*100)) > 1e-6 {
+		return 0, ErrPoints
+	}
+	return Round(points), nil
+}
+
+func Round(points float64) float64 {
+	return math.Round(points*100) / 100
+}
+
+// Check makes sure the points do not exceed the maximum.
+func Check(points float64, maxPoints float64) error {
+	if Round(points) > Round(maxPoints) {
+		return ErrExceedsMax
+	}
+	return nil
+}
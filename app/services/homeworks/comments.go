@@ -0,0 +1,51 @@
+package homeworks
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/sirupsen/logrus"
+)
+
+// Comment adds the actor's comment to the homework thread and notifies the other side.
+func Comment(actor *account.Actor, id uint, req forms.CreateCommentRequest) (*models.Comment, error) {
+	homework, err := find(actor, policy.Comment, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	comment := &models.Comment{
+		HomeworkId: homework.Id,
+		AuthorId:   actor.Id(),
+		AuthorRole: actor.Role,
+		Body:       req.Body,
+	}
+	if err := repository.Comment.Create(comment); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	if actor.IsTeacher() {
+		student, err := repository.Student.GetById(homework.StudentId)
+		if err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+		if err := mailer.NewComment(student.Email, student.Name, homework.Name, actor.Name(), comment.Body); err != nil {
+			logrus.WithError(err).Error("the comment email is not sent")
+		}
+	} else {
+		teacher, err := repository.Teacher.GetById(homework.TeacherId)
+		if err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+		if err := mailer.NewComment(teacher.Email, teacher.Name, homework.Name, actor.Name(), comment.Body); err != nil {
+			logrus.WithError(err).Error("the comment email is not sent")
+		}
+	}
+	return comment, nil
+}
@@ -0,0 +1,409 @@
+package homeworks
+
+import (
+	"mime/multipart"
+	"strconv"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/deadline"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/grading"
+	"github.com/MikhailR1337/task-sync-x/app/services/lifecycle"
+	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrStatus     = failure.New(failure.Conflict, "you cannot change the homework to this status")
+	ErrPoints     = failure.New(failure.Invalid, "points should be a number")
+	ErrPenalty    = failure.New(failure.Invalid, "late penalty should be a percent from 0 to 100")
+	ErrRecipients = failure.New(failure.Invalid, "choose your students or a group for the homework")
+	ErrType       = failure.New(failure.Invalid, "choose one of your homework types")
+	ErrRubric     = failure.New(failure.Invalid, "choose one of your rubrics")
+	ErrSubmission = failure.New(failure.Invalid, "add an answer or attach files to finish the homework")
+)
+
+// Detail is homework together with everything its page shows.
+type Detail struct {
+	Homework *models.Homework
+	Teacher  *models.Teacher
+	Student  *models.Student
+	Rubric   *models.Rubric
+	Attempts []models.Attempt
+	Comments []models.Comment
+	Deadline deadline.Status
+	// Statuses are the states the actor can move the homework to.
+	Statuses []lifecycle.State
+}
+
+// List returns the homework the actor gives or does.
+func List(actor *account.Actor, filter repository.HomeworkFilter) ([]models.Homework, error) {
+	var homeworks *[]models.Homework
+	var err error
+	if actor.IsTeacher() {
+		homeworks, err = repository.Homework.GetByTeacherId(actor.Teacher.Id, filter)
+	} else {
+		homeworks, err = repository.Homework.GetByStudentId(actor.Student.Id, filter)
+	}
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *homeworks, nil
+}
+
+// Create gives the same homework to every chosen student and notifies them.
+func Create(actor *account.Actor, req forms.CreateHomeworkRequest) ([]models.Homework, error) {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	teacher := actor.Teacher
+	var currentPoints float64
+	var err error
+	if req.CurrentPoints != "" {
+		currentPoints, err = grading.Parse(req.CurrentPoints)
+		if err != nil {
+			return nil, failure.Wrap(failure.Invalid, err)
+		}
+	}
+	homeworkType, err := repository.HomeworkType.GetByName(teacher.Id, req.Type)
+	if err != nil {
+		return nil, ErrType
+	}
+	maxPoints := homeworkType.DefaultMaxPoints
+	if req.MaxPoints != "" {
+		maxPoints, err = grading.Parse(req.MaxPoints)
+		if err != nil {
+			return nil, failure.Wrap(failure.Invalid, err)
+		}
+	}
+	var rubricId *uint
+	if req.Rubric != "" {
+		rubric, err := ownRubric(actor, req.Rubric)
+		if err != nil {
+			return nil, ErrRubric
+		}
+		rubricId = &rubric.Id
+		maxPoints = rubric.MaxPoints()
+		// rubrics made before their total was checked may not fit the points columns
+		if maxPoints > grading.Limit {
+			return nil, failure.Wrap(failure.Invalid, grading.ErrTotal)
+		}
+	}
+	if err := grading.Check(currentPoints, maxPoints); err != nil {
+		return nil, failure.Wrap(failure.Invalid, err)
+	}
+	students, err := recipients(actor, req.Students, req.Group)
+	if err != nil {
+		return nil, ErrRecipients
+	}
+	var dueAt *time.Time
+	if req.DueAt != "" {
+		due, err := time.ParseInLocation(deadline.InputLayout, req.DueAt, time.Local)
+		if err != nil {
+			return nil, failure.ErrValidation
+		}
+		dueAt = &due
+	}
+	var latePenalty uint64
+	if req.LatePenalty != "" {
+		latePenalty, err = strconv.ParseUint(req.LatePenalty, 10, 8)
+		if err != nil || latePenalty > 100 {
+			return nil, ErrPenalty
+		}
+	}
+
+	newHomeworks := make([]models.Homework, 0, len(students))
+	for _, student := range students {
+		newHomeworks = append(newHomeworks, models.Homework{
+			Name:          req.Name,
+			Description:   req.Description,
+			CurrentPoints: currentPoints,
+			MaxPoints:     maxPoints,
+			Type:          req.Type,
+			Status:        string(lifecycle.Initial),
+			TeacherId:     teacher.Id,
+			StudentId:     student.Id,
+			RubricId:      rubricId,
+			DueAt:         dueAt,
+			LatePenalty:   uint8(latePenalty),
+		})
+	}
+	if err := repository.Homework.CreateMany(&newHomeworks); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	for _, student := range students {
+		if err := mailer.NewHomework(student.Email, student.Name, req.Name); err != nil {
+			logrus.WithError(err).Error("the new homework email is not sent")
+		}
+	}
+	return newHomeworks, nil
+}
+
+func Get(actor *account.Actor, id uint) (*Detail, error) {
+	homework, err := find(actor, policy.View, id)
+	if err != nil {
+		return nil, err
+	}
+	teacher, err := repository.Teacher.GetById(homework.TeacherId)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	student, err := repository.Student.GetById(homework.StudentId)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	var rubric *models.Rubric
+	if homework.RubricId != nil {
+		rubric, err = repository.Rubric.GetById(*homework.RubricId)
+		if err != nil {
+			return nil, failure.ErrSomethingWrong
+		}
+	}
+	attempts, err := repository.Homework.GetAttempts(homework.Id)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	comments, err := repository.Comment.GetByHomeworkId(homework.Id)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return &Detail{
+		Homework: homework,
+		Teacher:  teacher,
+		Student:  student,
+		Rubric:   rubric,
+		Attempts: *attempts,
+		Comments: *comments,
+		Deadline: deadline.Check(homework.DueAt, homework.SubmittedAt, time.Now()),
+		Statuses: lifecycle.Next(lifecycle.State(homework.Status), actor.Role),
+	}, nil
+}
+
+// Update moves the homework to the requested status: the teacher reviews it, the student works on it.
+// files are the attachments the student finishes the homework with.
+func Update(actor *account.Actor, id uint, req forms.UpdateHomeworkRequest, files []*multipart.FileHeader) (*models.Homework, error) {
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	homework, err := find(actor, policy.Update, id)
+	if err != nil {
+		return nil, err
+	}
+	status, err := lifecycle.Transition(homework.Status, req.Status, actor.Role)
+	if err != nil {
+		return nil, ErrStatus
+	}
+	homework.Status = string(status)
+	if actor.IsTeacher() {
+		err = review(homework, status, req)
+	} else {
+		err = work(homework, status, req, files)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return homework, nil
+}
+
+func Delete(actor *account.Actor, id uint) error {
+	if _, err := find(actor, policy.Delete, id); err != nil {
+		return err
+	}
+	if err := repository.Homework.Delete(id); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// find loads the homework the actor is allowed to act on.
+func find(actor *account.Actor, action policy.Action, id uint) (*models.Homework, error) {
+	homework, err := repository.Homework.GetById(id)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	if err := policy.Homework(actor, action, homework); err != nil {
+		return nil, err
+	}
+	return homework, nil
+}
+
+// review saves the teacher's decision on the homework and notifies the student.
+func review(homework *models.Homework, status lifecycle.State, req forms.UpdateHomeworkRequest) error {
+	var scores []models.CriterionScore
+	if status == lifecycle.Checked {
+		points, criterionScores, err := grade(homework, req)
+		if err != nil {
+			return err
+		}
+		homework.CurrentPoints = deadline.Penalize(points, homework.LatePenalty, homework.DueAt, homework.SubmittedAt)
+		scores = criterionScores
+	}
+	var feedback *models.Comment
+	if req.Feedback != "" {
+		feedback = &models.Comment{
+			HomeworkId: homework.Id,
+			AuthorId:   homework.TeacherId,
+			AuthorRole: account.Teacher,
+			Body:       req.Feedback,
+		}
+	}
+	var err error
+	if status == lifecycle.Checked {
+		err = repository.Homework.Review(homework, &homework.CurrentPoints, scores, feedback, "")
+	} else if status == lifecycle.Returned {
+		err = repository.Homework.Review(homework, nil, nil, feedback, req.Reason)
+	} else {
+		err = repository.Homework.Reopen(homework, feedback)
+	}
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	student, err := repository.Student.GetById(homework.StudentId)
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if status == lifecycle.Checked {
+		err = mailer.CheckedHomework(student.Email, student.Name, homework.Name, homework.CurrentPoints, homework.MaxPoints, scores)
+	} else {
+		err = mailer.UpdatedHomework(student.Email, student.Name, homework.Name, homework.Status)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("the homework email is not sent")
+	}
+	return nil
+}
+
+// work saves the student's progress on the homework and notifies the teacher.
+func work(homework *models.Homework, status lifecycle.State, req forms.UpdateHomeworkRequest, files []*multipart.FileHeader) error {
+	if status == lifecycle.Finished {
+		submission, err := saveSubmission(homework, req.Answer, files)
+		if err != nil {
+			return err
+		}
+		submittedAt := time.Now()
+		homework.SubmittedAt = &submittedAt
+		if err := repository.Homework.Submit(homework, submission); err != nil {
+			deleteFiles(submission.Files)
+			return failure.ErrSomethingWrong
+		}
+	} else if err := repository.Homework.Update(homework); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	teacher, err := repository.Teacher.GetById(homework.TeacherId)
+	if err != nil {
+		return failure.ErrSomethingWrong
+	}
+	if err := mailer.UpdatedHomework(teacher.Email, teacher.Name, homework.Name, homework.Status); err != nil {
+		logrus.WithError(err).Error("the homework email is not sent")
+	}
+	return nil
+}
+
+// grade computes the points of checked homework, criterion by criterion when it has a rubric.
+func grade(homework *models.Homework, req forms.UpdateHomeworkRequest) (float64, []models.CriterionScore, error) {
+	if homework.RubricId == nil {
+		if req.CurrentPoints == "" {
+			return 0, nil, ErrPoints
+		}
+		points, err := grading.Parse(req.CurrentPoints)
+		if err != nil {
+			return 0, nil, failure.Wrap(failure.Invalid, err)
+		}
+		if err := grading.Check(points, homework.MaxPoints); err != nil {
+			return 0, nil, failure.Wrap(failure.Invalid, err)
+		}
+		return points, nil, nil
+	}
+	rubric, err := repository.Rubric.GetById(*homework.RubricId)
+	if err != nil {
+		return 0, nil, failure.ErrSomethingWrong
+	}
+	var total float64
+	scores := make([]models.CriterionScore, 0, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		points, err := grading.Parse(req.Criteria[strconv.FormatUint(uint64(criterion.Id), 10)])
+		if err != nil {
+			return 0, nil, failure.Wrap(failure.Invalid, err)
+		}
+		if err := grading.Check(points, criterion.MaxPoints); err != nil {
+			return 0, nil, failure.Wrap(failure.Invalid, err)
+		}
+		total += points
+		scores = append(scores, models.CriterionScore{
+			CriterionId: criterion.Id,
+			Name:        criterion.Name,
+			Points:      points,
+			MaxPoints:   criterion.MaxPoints,
+		})
+	}
+	return grading.Round(total), scores, nil
+}
+
+func ownRubric(actor *account.Actor, param string) (*models.Rubric, error) {
+	rubricId, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	rubric, err := repository.Rubric.GetById(uint(rubricId))
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	return rubric, policy.Rubric(actor, policy.View, rubric)
+}
+
+// recipients collects the teacher's students chosen directly or through a group.
+func recipients(actor *account.Actor, studentParams []string, groupParam string) ([]models.Student, error) {
+	candidates := []models.Student{}
+	if groupParam != "" {
+		groupId, err := strconv.ParseUint(groupParam, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		group, err := repository.Group.GetById(uint(groupId))
+		if err != nil {
+			return nil, failure.ErrNotFound
+		}
+		if err := policy.Group(actor, policy.View, group); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, group.Students...)
+	}
+	if len(studentParams) > 0 {
+		ids := make([]uint, 0, len(studentParams))
+		for _, param := range studentParams {
+			id, err := strconv.ParseUint(param, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, uint(id))
+		}
+		students, err := repository.Student.GetByIds(ids)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, *students...)
+	}
+	recipients := []models.Student{}
+	added := map[uint]bool{}
+	for i := range candidates {
+		student := &candidates[i]
+		if policy.Student(actor, policy.Update, student) != nil || added[student.Id] {
+			continue
+		}
+		added[student.Id] = true
+		recipients = append(recipients, *student)
+	}
+	if len(recipients) == 0 {
+		return nil, ErrRecipients
+	}
+	return recipients, nil
+}
@@ -0,0 +1,77 @@
+package homeworks
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/sirupsen/logrus"
+)
+
+// File opens a file attached to the homework. The caller closes the reader.
+func File(actor *account.Actor, homeworkId uint, id uint) (*models.SubmissionFile, io.ReadCloser, error) {
+	if _, err := find(actor, policy.View, homeworkId); err != nil {
+		return nil, nil, err
+	}
+	file, err := repository.Submission.GetFile(homeworkId, id)
+	if err != nil {
+		return nil, nil, failure.ErrNotFound
+	}
+	reader, err := initializers.Storage.Open(file.Key)
+	if err != nil {
+		return nil, nil, failure.ErrNotFound
+	}
+	return file, reader, nil
+}
+
+// saveSubmission stores the uploaded files and returns the submission the homework is finished with.
+func saveSubmission(homework *models.Homework, answer string, headers []*multipart.FileHeader) (*models.Submission, error) {
+	if strings.TrimSpace(answer) == "" && len(headers) == 0 {
+		return nil, ErrSubmission
+	}
+	submission := &models.Submission{
+		HomeworkId: homework.Id,
+		Answer:     answer,
+	}
+	for _, header := range headers {
+		name := filepath.Base(header.Filename)
+		key := fmt.Sprintf("homeworks/%d/%d-%s", homework.Id, time.Now().UnixNano(), name)
+		if err := saveFile(key, header); err != nil {
+			deleteFiles(submission.Files)
+			return nil, failure.ErrSomethingWrong
+		}
+		submission.Files = append(submission.Files, models.SubmissionFile{
+			Name:        name,
+			Key:         key,
+			Size:        header.Size,
+			ContentType: header.Header.Get("Content-Type"),
+		})
+	}
+	return submission, nil
+}
+
+func saveFile(key string, header *multipart.FileHeader) error {
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return initializers.Storage.Save(key, file)
+}
+
+func deleteFiles(files []models.SubmissionFile) {
+	for _, file := range files {
+		if err := initializers.Storage.Delete(file.Key); err != nil {
+			logrus.WithError(err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package joins
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/application/forms"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+	"github.com/MikhailR1337/task-sync-x/app/initializers"
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+	"github.com/MikhailR1337/task-sync-x/app/services/mailer"
+	"github.com/MikhailR1337/task-sync-x/app/services/policy"
+	"github.com/sirupsen/logrus"
+)
+
+// Statuses of a join request.
+const (
+	Pending  = "pending"
+	Accepted = "accepted"
+	Rejected = "rejected"
+)
+
+var (
+	ErrJoined   = failure.New(failure.Conflict, "you already study with this teacher")
+	ErrPending  = failure.New(failure.Conflict, "you have already asked this teacher, wait for the answer")
+	ErrAnswered = failure.New(failure.Conflict, "the request has already been answered")
+	ErrInvite   = failure.New(failure.NotFound, "the invite link is not valid anymore, ask the teacher for a new one")
+)
+
+// Request asks the teacher to take the student into the class and lets the teacher know.
+func Request(actor *account.Actor, req forms.CreateJoinRequest) (*models.JoinRequest, error) {
+	if err := policy.StudentOnly(actor); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	teacherId, err := strconv.ParseUint(req.Teacher, 10, 32)
+	if err != nil {
+		return nil, account.ErrTeacher
+	}
+	teacher, err := repository.Teacher.GetById(uint(teacherId))
+	if err != nil {
+		return nil, account.ErrTeacher
+	}
+	if enrollment := actor.Student.EnrollmentWith(teacher.Id); enrollment != nil && enrollment.Active() {
+		return nil, ErrJoined
+	}
+	pending, err := repository.JoinRequest.GetByStudentId(actor.Student.Id, Pending)
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	for _, request := range *pending {
+		if request.TeacherId == teacher.Id {
+			return nil, ErrPending
+		}
+	}
+	request := &models.JoinRequest{
+		StudentId: actor.Student.Id,
+		TeacherId: teacher.Id,
+		Status:    Pending,
+	}
+	if err := repository.JoinRequest.Create(request); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	request.Teacher = *teacher
+	if err := mailer.JoinRequested(teacher.Email, teacher.Name, actor.Name()); err != nil {
+		logrus.WithError(err).Error("the join request email is not sent")
+	}
+	return request, nil
+}
+
+// PendingOf returns the requests waiting for the teacher's answer, or the ones the student is waiting on.
+func PendingOf(actor *account.Actor) ([]models.JoinRequest, error) {
+	var requests *[]models.JoinRequest
+	var err error
+	if actor.IsTeacher() {
+		requests, err = repository.JoinRequest.GetByTeacherId(actor.Teacher.Id, Pending)
+	} else {
+		requests, err = repository.JoinRequest.GetByStudentId(actor.Student.Id, Pending)
+	}
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *requests, nil
+}
+
+// Answer accepts or rejects the request and lets the student know.
+func Answer(actor *account.Actor, id uint, req forms.AnswerJoinRequest) (*models.JoinRequest, error) {
+	request, err := repository.JoinRequest.GetById(id)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	if err := policy.JoinRequest(actor, policy.Update, request); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	if request.Status != Pending {
+		return nil, ErrAnswered
+	}
+	if req.Status == Accepted {
+		if err := Enroll(&request.Student, actor.Teacher); err != nil {
+			return nil, err
+		}
+	}
+	request.Status = req.Status
+	if err := repository.JoinRequest.Update(request); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	if err := mailer.JoinAnswered(request.Student.Email, request.Student.Name, actor.Name(), request.Status); err != nil {
+		logrus.WithError(err).Error("the join answer email is not sent")
+	}
+	return request, nil
+}
+
+// Invite gives the teacher a new invite code. The code they shared before stops working.
+func Invite(actor *account.Actor) (*models.Invite, error) {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
+	}
+	code, err := newCode()
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	invite := &models.Invite{TeacherId: actor.Teacher.Id, Code: code}
+	if err := repository.Invite.Save(invite); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return invite, nil
+}
+
+// InviteOf returns the teacher's invite, nil when they have not made one yet.
+func InviteOf(actor *account.Actor) (*models.Invite, error) {
+	if err := policy.TeacherOnly(actor); err != nil {
+		return nil, err
+	}
+	invite, err := repository.Invite.GetByTeacherId(actor.Teacher.Id)
+	if err != nil {
+		return nil, nil
+	}
+	return invite, nil
+}
+
+// Join takes the student into the class of the teacher whose invite code they followed, without waiting for approval.
+func Join(actor *account.Actor, code string) (*models.Teacher, error) {
+	if err := policy.StudentOnly(actor); err != nil {
+		return nil, err
+	}
+	invite, err := repository.Invite.GetByCode(code)
+	if err != nil {
+		return nil, ErrInvite
+	}
+	teacher, err := repository.Teacher.GetById(invite.TeacherId)
+	if err != nil {
+		return nil, ErrInvite
+	}
+	if enrollment := actor.Student.EnrollmentWith(teacher.Id); enrollment != nil && enrollment.Active() {
+		return teacher, nil
+	}
+	if err := Enroll(actor.Student, teacher); err != nil {
+		return nil, err
+	}
+	// the invite answers the request the student may have sent before following it
+	if err := repository.JoinRequest.UpdateStatus(actor.Student.Id, teacher.Id, Pending, Accepted); err != nil {
+		logrus.WithError(err)
+	}
+	if err := mailer.JoinedByInvite(teacher.Email, teacher.Name, actor.Name()); err != nil {
+		logrus.WithError(err).Error("the invite email is not sent")
+	}
+	return teacher, nil
+}
+
+// EnrollmentsOf returns the teacher's students or the student's teachers, the current ones first.
+func EnrollmentsOf(actor *account.Actor) ([]models.Enrollment, error) {
+	var enrollments *[]models.Enrollment
+	var err error
+	if actor.IsTeacher() {
+		enrollments, err = repository.Enrollment.GetByTeacherId(actor.Teacher.Id)
+	} else {
+		enrollments, err = repository.Enrollment.GetByStudentId(actor.Student.Id)
+	}
+	if err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return *enrollments, nil
+}
+
+// UpdateEnrollment finishes the student's studies with the teacher or starts them again.
+// Students who finished keep their homework but get no new one.
+func UpdateEnrollment(actor *account.Actor, id uint, req forms.UpdateEnrollmentRequest) (*models.Enrollment, error) {
+	enrollment, err := repository.Enrollment.GetById(id)
+	if err != nil {
+		return nil, failure.ErrNotFound
+	}
+	if err := policy.Enrollment(actor, policy.Update, enrollment); err != nil {
+		return nil, err
+	}
+	if err := initializers.Validator.Struct(req); err != nil {
+		return nil, failure.Validation(err)
+	}
+	if enrollment.Status == req.Status {
+		return enrollment, nil
+	}
+	now := time.Now()
+	enrollment.Status = req.Status
+	if enrollment.Active() {
+		enrollment.StartsAt = now
+		enrollment.EndsAt = nil
+	} else {
+		enrollment.EndsAt = &now
+	}
+	if err := repository.Enrollment.Update(enrollment); err != nil {
+		return nil, failure.ErrSomethingWrong
+	}
+	return enrollment, nil
+}
+
+// Enroll starts the student's studies with the teacher.
+func Enroll(student *models.Student, teacher *models.Teacher) error {
+	enrollment := &models.Enrollment{
+		StudentId: student.Id,
+		TeacherId: teacher.Id,
+		Status:    models.EnrollmentActive,
+		StartsAt:  time.Now(),
+	}
+	if err := repository.Enrollment.Save(enrollment); err != nil {
+		return failure.ErrSomethingWrong
+	}
+	return nil
+}
+
+// newCode returns a random code that is safe to put into a link.
+func newCode() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,89 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+)
+
+type State string
+
+const (
+	New        State = "new"
+	Processing State = "processing"
+	Finished   State = "finished"
+	Checked    State = "checked"
+	Returned   State = "returned"
+)
+
+// Initial is the state every homework starts with.
+const Initial = New
+
+var ErrUnknownState = errors.New("unknown homework status")
+
+// TransitionError is returned when the role is not allowed to move
+// homework from one state to another.
+type TransitionError struct {
+	Role string
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s cannot change homework status from %s to %s", e.Role, e.From, e.To)
+}
+
+var states = []State{New, Processing, Finished, Checked, Returned}
+
+// transitions lists the states each role can move homework to from a given state.
+var transitions = map[string]map[State][]State{
+	account.Student: {
+		New:        {Processing},
+		Processing: {Finished},
+		Returned:   {Processing},
+	},
+	account.Teacher: {
+		Finished: {Checked, Returned},
+		Checked:  {Processing},
+	},
+}
+
+func Parse(s string) (State, error) {
+	for _, state := range states {
+		if string(state) == s {
+			return state, nil
+		}
+	}
+	return "", ErrUnknownState
+}
+
+// Next returns the states the role can move homework to from the given state.
+func Next(from State, role string) []State {
+	return transitions[role][from]
+}
+
+func Can(from State, to State, role string) bool {
+	for _, state := range Next(from, role) {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition validates the move and returns the new state.
+func Transition(from string, to string, role string) (State, error) {
+	fromState, err := Parse(from)
+	if err != nil {
+		return "", err
+	}
+	toState, err := Parse(to)
+	if err != nil {
+		return "", err
+	}
+	if !Can(fromState, toState, role) {
+		return "", &TransitionError{Role: role, From: fromState, To: toState}
+	}
+	return toState, nil
+}
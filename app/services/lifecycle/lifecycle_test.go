@@ -0,0 +1,68 @@
+package lifecycle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/MikhailR1337/task-sync-x/app/services/account"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		from State
+		role string
+		want []State
+	}{
+		{New, account.Student, []State{Processing}},
+		{Processing, account.Student, []State{Finished}},
+		{Finished, account.Student, nil},
+		{Checked, account.Student, nil},
+		{Returned, account.Student, []State{Processing}},
+
+		{New, account.Teacher, nil},
+		{Processing, account.Teacher, nil},
+		{Finished, account.Teacher, []State{Checked, Returned}},
+		{Checked, account.Teacher, []State{Processing}},
+		{Returned, account.Teacher, nil},
+
+		{New, account.Admin, nil},
+		{Processing, account.Admin, nil},
+		{Finished, account.Admin, nil},
+		{Checked, account.Admin, nil},
+		{Returned, account.Admin, nil},
+	}
+	for _, tt := range tests {
+		got := Next(tt.from, tt.role)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Next(%s, %s) = %v, want %v", tt.from, tt.role, got, tt.want)
+		}
+		for _, to := range states {
+			want := false
+			for _, state := range tt.want {
+				want = want || state == to
+			}
+			if got := Can(tt.from, to, tt.role); got != want {
+				t.Errorf("Can(%s, %s, %s) = %v, want %v", tt.from, to, tt.role, got, want)
+			}
+		}
+	}
+}
+
+func TestTransition(t *testing.T) {
+	if got, err := Transition("finished", "checked", account.Teacher); err != nil || got != Checked {
+		t.Errorf("Transition(finished, checked, teacher) = %q, %v, want checked", got, err)
+	}
+	if _, err := Transition("archived", "checked", account.Teacher); !errors.Is(err, ErrUnknownState) {
+		t.Errorf("an unknown current status: %v, want %v", err, ErrUnknownState)
+	}
+	if _, err := Transition("finished", "graded", account.Teacher); !errors.Is(err, ErrUnknownState) {
+		t.Errorf("an unknown new status: %v, want %v", err, ErrUnknownState)
+	}
+	var transitionErr *TransitionError
+	if _, err := Transition("finished", "checked", account.Student); !errors.As(err, &transitionErr) {
+		t.Errorf("a student checking homework: %v, want a TransitionError", err)
+	} else if transitionErr.Role != account.Student || transitionErr.From != Finished || transitionErr.To != Checked {
+		t.Errorf("the error describes %+v", *transitionErr)
+	}
+}
@@ -0,0 +1,120 @@
+// Package lockout slows down and then locks out keys, like an account or an IP address, that keep failing to sign in.
+// The failures are counted in a Store, in memory for a single server or in Postgres to share them between servers.
+package lockout
+
+import (
+	"time"
+)
+
+// Policy says how many failures go through at once, how the wait grows after them and when the key is locked.
+type Policy struct {
+	// Free is how many failures do not make the key wait.
+	Free int
+	// Delay is the wait after the first failure past Free. It doubles with every next failure up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+	// LockAfter is how many failures lock the key for LockFor.
+	LockAfter int
+	LockFor   time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// Attempts are the failures counted for a key.
+type Attempts struct {
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  time.Time
+}
+
+// Store keeps the failures of every key.
+type Store interface {
+	// Get returns the attempts of the key, zero ones when it has not failed.
+	Get(key string) (Attempts, error)
+	// Fail counts a failure of the key at the time and returns the attempts with it.
+	Fail(key string, at time.Time) (Attempts, error)
+	// Lock keeps the key locked until the time.
+	Lock(key string, until time.Time) error
+	// Reset forgets the failures of the key.
+	Reset(key string) error
+}
+
+// Limiter applies the policy to the keys counted in the store.
+type Limiter struct {
+	store  Store
+	policy Policy
+	clock  func() time.Time
+}
+
+func New(store Store, policy Policy) *Limiter {
+	return &Limiter{store: store, policy: policy, clock: time.Now}
+}
+
+// WithClock returns the limiter telling the time with clock instead of time.Now.
+func (l *Limiter) WithClock(clock func() time.Time) *Limiter {
+	return &Limiter{store: l.store, policy: l.policy, clock: clock}
+}
+
+// Wait returns how long the key has to wait before it may try again, zero when it may try now.
+func (l *Limiter) Wait(key string) (time.Duration, error) {
+	attempts, err := l.store.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	return l.wait(attempts, l.clock()), nil
+}
+
+// Fail counts a failure of the key and reports whether it locked the key.
+func (l *Limiter) Fail(key string) (bool, error) {
+	now := l.clock()
+	attempts, err := l.store.Get(key)
+	if err != nil {
+		return false, err
+	}
+	if l.expired(attempts, now) {
+		if err := l.store.Reset(key); err != nil {
+			return false, err
+		}
+	}
+	attempts, err = l.store.Fail(key, now)
+	if err != nil {
+		return false, err
+	}
+	if attempts.Failures < l.policy.LockAfter || attempts.LockedUntil.After(now) {
+		return false, nil
+	}
+	if err := l.store.Lock(key, now.Add(l.policy.LockFor)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Succeed forgets the failures of the key.
+func (l *Limiter) Succeed(key string) error {
+	return l.store.Reset(key)
+}
+
+func (l *Limiter) wait(attempts Attempts, now time.Time) time.Duration {
+	if attempts.LockedUntil.After(now) {
+		return attempts.LockedUntil.Sub(now)
+	}
+	if l.expired(attempts, now) || attempts.Failures <= l.policy.Free {
+		return 0
+	}
+	delay := l.policy.Delay
+	for i := l.policy.Free + 1; i < attempts.Failures && delay < l.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > l.policy.MaxDelay {
+		delay = l.policy.MaxDelay
+	}
+	if wait := attempts.LastFailedAt.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// expired reports whether the failures are old enough to be forgotten.
+func (l *Limiter) expired(attempts Attempts, now time.Time) bool {
+	return attempts.Failures > 0 && !attempts.LockedUntil.After(now) && now.Sub(attempts.LastFailedAt) > l.policy.Window
+}
@@ -0,0 +1,129 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+var policy = Policy{
+	Free:      3,
+	Delay:     time.Second,
+	MaxDelay:  8 * time.Second,
+	LockAfter: 8,
+	LockFor:   15 * time.Minute,
+	Window:    time.Hour,
+}
+
+// clock is a time that only moves when the test moves it.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newLimiter() (*Limiter, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	return New(NewMemoryStore(), policy).WithClock(c.Now), c
+}
+
+func fail(t *testing.T, l *Limiter, key string) bool {
+	t.Helper()
+	locked, err := l.Fail(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return locked
+}
+
+func wait(t *testing.T, l *Limiter, key string) time.Duration {
+	t.Helper()
+	w, err := l.Wait(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestBackoff(t *testing.T) {
+	l, _ := newLimiter()
+	// the wait after every failure: none for the free ones, then doubling up to MaxDelay
+	want := []time.Duration{0, 0, 0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	for i, w := range want {
+		if fail(t, l, "account") {
+			t.Fatalf("failure %d locked the key", i+1)
+		}
+		if got := wait(t, l, "account"); got != w {
+			t.Errorf("after failure %d: wait %v, want %v", i+1, got, w)
+		}
+	}
+	if got := wait(t, l, "other"); got != 0 {
+		t.Errorf("another key: wait %v, want 0", got)
+	}
+}
+
+func TestWaitPasses(t *testing.T) {
+	l, c := newLimiter()
+	for i := 0; i < 5; i++ {
+		fail(t, l, "account")
+	}
+	c.now = c.now.Add(time.Second)
+	if got := wait(t, l, "account"); got != time.Second {
+		t.Errorf("a second later: wait %v, want 1s", got)
+	}
+	c.now = c.now.Add(time.Second)
+	if got := wait(t, l, "account"); got != 0 {
+		t.Errorf("two seconds later: wait %v, want 0", got)
+	}
+}
+
+func TestLockout(t *testing.T) {
+	l, c := newLimiter()
+	for i := 1; i < policy.LockAfter; i++ {
+		if fail(t, l, "account") {
+			t.Fatalf("failure %d locked the key", i)
+		}
+	}
+	if !fail(t, l, "account") {
+		t.Fatal("the last failure did not lock the key")
+	}
+	if got := wait(t, l, "account"); got != policy.LockFor {
+		t.Errorf("locked: wait %v, want %v", got, policy.LockFor)
+	}
+	c.now = c.now.Add(policy.LockFor)
+	if got := wait(t, l, "account"); got != 0 {
+		t.Errorf("after the lock: wait %v, want 0", got)
+	}
+	if !fail(t, l, "account") {
+		t.Error("a failure after the lock did not lock the key again")
+	}
+}
+
+func TestSucceedResets(t *testing.T) {
+	l, _ := newLimiter()
+	for i := 0; i < 5; i++ {
+		fail(t, l, "account")
+	}
+	if err := l.Succeed("account"); err != nil {
+		t.Fatal(err)
+	}
+	if got := wait(t, l, "account"); got != 0 {
+		t.Errorf("after success: wait %v, want 0", got)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	l, c := newLimiter()
+	for i := 0; i < 5; i++ {
+		fail(t, l, "account")
+	}
+	c.now = c.now.Add(policy.Window + time.Second)
+	if got := wait(t, l, "account"); got != 0 {
+		t.Errorf("after the window: wait %v, want 0", got)
+	}
+	fail(t, l, "account")
+	if got := wait(t, l, "account"); got != 0 {
+		t.Errorf("first failure after the window: wait %v, want 0", got)
+	}
+}
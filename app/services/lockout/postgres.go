@@ -0,0 +1,47 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
+)
+
+// PostgresStore keeps the failures in the database, where every server sees them.
+type PostgresStore struct{}
+
+func NewPostgresStore() *PostgresStore {
+	return &PostgresStore{}
+}
+
+func (s *PostgresStore) Get(key string) (Attempts, error) {
+	attempt, err := repository.LoginAttempt.Get(key)
+	if err != nil || attempt == nil {
+		return Attempts{}, err
+	}
+	return attemptsOf(attempt), nil
+}
+
+func (s *PostgresStore) Fail(key string, at time.Time) (Attempts, error) {
+	attempt, err := repository.LoginAttempt.Fail(key, at)
+	if err != nil {
+		return Attempts{}, err
+	}
+	return attemptsOf(attempt), nil
+}
+
+func (s *PostgresStore) Lock(key string, until time.Time) error {
+	return repository.LoginAttempt.Lock(key, until)
+}
+
+func (s *PostgresStore) Reset(key string) error {
+	return repository.LoginAttempt.Delete(key)
+}
+
+func attemptsOf(attempt *models.LoginAttempt) Attempts {
+	attempts := Attempts{Failures: attempt.Failures, LastFailedAt: attempt.LastFailedAt}
+	if attempt.LockedUntil != nil {
+		attempts.LockedUntil = *attempt.LockedUntil
+	}
+	return attempts
+}
@@ -4,90 +4,190 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"net/http"
-	"text/template"
 
 	"github.com/MikhailR1337/task-sync-x/app/application/forms"
-	"github.com/sirupsen/logrus"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/repository"
 )
 
 const addr = "http://mailer:3001/email"
 const conType = "application/json"
 
-func CheckedHomework(email string, name string, hwName string) {
-	notification := forms.Mailer{}
-	notification.Email = email
-	notification.Subject = "Homework has checked"
-	var body bytes.Buffer
-	t, err := template.ParseFiles("public/template/email/checked.html")
+func CheckedHomework(email string, name string, hwName string, points float64, maxPoints float64, scores []models.CriterionScore) error {
+	message, err := compose(email, "Homework has checked", "checked", struct {
+		Name      string
+		HwName    string
+		Points    float64
+		MaxPoints float64
+		Scores    []models.CriterionScore
+	}{Name: name, HwName: hwName, Points: points, MaxPoints: maxPoints, Scores: scores})
 	if err != nil {
-		logrus.WithError(err)
+		return err
 	}
-	fmt.Println(t)
-	t.Execute(&body, struct {
+	return notify(email, message)
+}
+
+func UpdatedHomework(email string, name string, hwName string, status string) error {
+	message, err := compose(email, "Homework's status was changed", "updated", struct {
 		Name   string
 		HwName string
-	}{Name: name, HwName: hwName})
-	notification.Template = body.String()
-	JsonValue, err := json.Marshal(notification)
+		Status string
+	}{Name: name, HwName: hwName, Status: status})
 	if err != nil {
-		logrus.WithError(err)
+		return err
 	}
-	sendEmail(JsonValue)
+	return notify(email, message)
 }
 
-func UpdatedHomework(email string, name string, hwName string, status string) {
-	notification := forms.Mailer{}
-	notification.Email = email
-	notification.Subject = "Homework's status was changed"
-	var body bytes.Buffer
-	t, err := template.ParseFiles("public/template/email/updated.html")
+func NewHomework(email string, name string, hwName string) error {
+	message, err := compose(email, "You have a new homework", "new", struct {
+		Name   string
+		HwName string
+	}{Name: name, HwName: hwName})
 	if err != nil {
-		logrus.WithError(err)
+		return err
 	}
-	fmt.Println(t)
-	t.Execute(&body, struct {
+	return notify(email, message)
+}
+
+func NewComment(email string, name string, hwName string, author string, text string) error {
+	message, err := compose(email, "New comment on homework", "comment", struct {
 		Name   string
 		HwName string
-		Status string
-	}{Name: name, HwName: hwName, Status: status})
-	notification.Template = body.String()
-	JsonValue, err := json.Marshal(notification)
+		Author string
+		Text   string
+	}{Name: name, HwName: hwName, Author: author, Text: text})
 	if err != nil {
-		logrus.WithError(err)
+		return err
 	}
-	sendEmail(JsonValue)
+	return notify(email, message)
 }
 
-func NewHomework(email string, name string, hwName string) {
-	notification := forms.Mailer{}
-	notification.Email = email
-	notification.Subject = "You have a new homework"
-	var body bytes.Buffer
-	t, err := template.ParseFiles("public/template/email/new.html")
+func JoinRequested(email string, name string, student string) error {
+	message, err := compose(email, "A student asks to join your class", "join_request", struct {
+		Name    string
+		Student string
+	}{Name: name, Student: student})
 	if err != nil {
-		logrus.WithError(err)
+		return err
 	}
-	fmt.Println(t)
-	t.Execute(&body, struct {
-		Name   string
-		HwName string
-	}{Name: name, HwName: hwName})
-	notification.Template = body.String()
-	JsonValue, err := json.Marshal(notification)
+	return notify(email, message)
+}
+
+func JoinAnswered(email string, name string, teacher string, status string) error {
+	message, err := compose(email, "Your request to join the class was answered", "join_answer", struct {
+		Name    string
+		Teacher string
+		Status  string
+	}{Name: name, Teacher: teacher, Status: status})
+	if err != nil {
+		return err
+	}
+	return notify(email, message)
+}
+
+func JoinedByInvite(email string, name string, student string) error {
+	message, err := compose(email, "A student joined your class", "joined", struct {
+		Name    string
+		Student string
+	}{Name: name, Student: student})
+	if err != nil {
+		return err
+	}
+	return notify(email, message)
+}
+
+func PasswordReset(email string, name string, link string, expiresIn string) error {
+	message, err := compose(email, "Reset your password", "password_reset", struct {
+		Name      string
+		Link      string
+		ExpiresIn string
+	}{Name: name, Link: link, ExpiresIn: expiresIn})
+	if err != nil {
+		return err
+	}
+	return sendEmail(message)
+}
+
+func Verification(email string, name string, link string, expiresIn string) error {
+	message, err := compose(email, "Confirm your email", "verification", struct {
+		Name      string
+		Link      string
+		ExpiresIn string
+	}{Name: name, Link: link, ExpiresIn: expiresIn})
+	if err != nil {
+		return err
+	}
+	return sendEmail(message)
+}
+
+func Lockout(email string, name string, lockedFor string) error {
+	message, err := compose(email, "Your account is locked", "lockout", struct {
+		Name      string
+		LockedFor string
+	}{Name: name, LockedFor: lockedFor})
 	if err != nil {
-		logrus.WithError(err)
+		return err
 	}
-	sendEmail(JsonValue)
+	return sendEmail(message)
 }
 
-func sendEmail(body []byte) {
-	_, err := http.Post(
+// EmailChanged tells the old address the account moved to the new one, whether or not it was confirmed.
+func EmailChanged(email string, name string, newEmail string) error {
+	message, err := compose(email, "The email of your account was changed", "email_changed", struct {
+		Name     string
+		NewEmail string
+	}{Name: name, NewEmail: newEmail})
+	if err != nil {
+		return err
+	}
+	return sendEmail(message)
+}
+
+// render fills the email template with the name in with the data.
+func render(name string, data interface{}) (string, error) {
+	t, err := template.ParseFiles("public/template/email/" + name + ".html")
+	if err != nil {
+		return "", err
+	}
+	var body bytes.Buffer
+	if err := t.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+// compose renders the template into the message the mailer service sends to the address.
+func compose(email string, subject string, name string, data interface{}) ([]byte, error) {
+	body, err := render(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(forms.Mailer{Email: email, Subject: subject, Template: body})
+}
+
+// notify sends the notification only to confirmed addresses, so mistyped ones get nothing.
+func notify(email string, message []byte) error {
+	if !repository.User.IsVerified(email) {
+		return nil
+	}
+	return sendEmail(message)
+}
+
+func sendEmail(message []byte) error {
+	resp, err := http.Post(
 		addr,
 		conType,
-		bytes.NewBuffer(body),
+		bytes.NewBuffer(message),
 	)
 	if err != nil {
-		logrus.WithError(err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("the mailer answered %s", resp.Status)
 	}
+	return nil
 }
@@ -0,0 +1,307 @@
+// Package oidc signs users in with an OpenID Connect identity provider. It discovers the provider, sends users
+// to its sign in page with the authorization code flow and PKCE, and verifies the ID token the provider returns.
+// ID tokens have to be signed with RS256, which every provider supports.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Leeway is how far the clocks of the provider and the application may drift apart.
+const Leeway = time.Minute
+
+var (
+	ErrDiscovery = errors.New("oidc: the provider configuration cannot be discovered")
+	ErrExchange  = errors.New("oidc: the code cannot be exchanged for tokens")
+	ErrIdToken   = errors.New("oidc: the ID token is not valid")
+)
+
+// Config is how the provider knows the application.
+type Config struct {
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	// Scopes are asked for besides openid.
+	Scopes []string
+	// RoleClaim names the claim with the roles of the user, a string or an array of them.
+	RoleClaim string
+}
+
+// Metadata is the part of the discovery document signing in needs.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// Claims are what the verified ID token tells about the user.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Roles         []string
+}
+
+// Provider is a discovered identity provider.
+type Provider struct {
+	config   Config
+	metadata Metadata
+	client   *http.Client
+	clock    func() time.Time
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+// Discover reads the configuration the provider publishes at its well-known address.
+func Discover(config Config, client *http.Client) (*Provider, error) {
+	metadata := Metadata{}
+	address := strings.TrimSuffix(config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(client, address, &metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if metadata.Issuer != config.Issuer {
+		return nil, fmt.Errorf("%w: the issuer is %q, not %q", ErrDiscovery, metadata.Issuer, config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksUri == "" {
+		return nil, fmt.Errorf("%w: endpoints are missing", ErrDiscovery)
+	}
+	return &Provider{config: config, metadata: metadata, client: client, clock: time.Now}, nil
+}
+
+// WithClock returns the provider telling the time with clock instead of time.Now.
+func (p *Provider) WithClock(clock func() time.Time) *Provider {
+	return &Provider{config: p.config, metadata: p.metadata, client: p.client, clock: clock}
+}
+
+// Issuer is the provider the users come from.
+func (p *Provider) Issuer() string {
+	return p.metadata.Issuer
+}
+
+// AuthCodeURL is the sign in page of the provider. It sends the user back to redirectUrl with a code and the state;
+// the ID token carries the nonce, and only who knows the verifier can exchange the code.
+func (p *Provider) AuthCodeURL(redirectUrl string, state string, nonce string, verifier string) string {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientId},
+		"redirect_uri":          {redirectUrl},
+		"scope":                 {strings.Join(append([]string{"openid"}, p.config.Scopes...), " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange trades the code for tokens and returns the claims of the ID token once it is verified.
+func (p *Provider) Exchange(redirectUrl string, code string, verifier string, nonce string) (*Claims, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectUrl},
+		"code_verifier": {verifier},
+		"client_id":     {p.config.ClientId},
+	}
+	req, err := http.NewRequest(http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: the provider answered %s", ErrExchange, res.Status)
+	}
+	tokens := struct {
+		IdToken string `json:"id_token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&tokens); err != nil || tokens.IdToken == "" {
+		return nil, fmt.Errorf("%w: the answer has no ID token", ErrExchange)
+	}
+	return p.Verify(tokens.IdToken, nonce)
+}
+
+// Verify checks the signature, issuer, audience, times and nonce of the ID token and returns its claims.
+func (p *Provider) Verify(idToken string, nonce string) (*Claims, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithoutClaimsValidation())
+	_, err := parser.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIdToken, err)
+	}
+	now := p.clock()
+	switch {
+	case !claims.VerifyIssuer(p.metadata.Issuer, true):
+		return nil, fmt.Errorf("%w: it is issued by someone else", ErrIdToken)
+	case !claims.VerifyAudience(p.config.ClientId, true):
+		return nil, fmt.Errorf("%w: it is meant for someone else", ErrIdToken)
+	case !claims.VerifyExpiresAt(now.Add(-Leeway).Unix(), true):
+		return nil, fmt.Errorf("%w: it has expired", ErrIdToken)
+	case !claims.VerifyIssuedAt(now.Add(Leeway).Unix(), false):
+		return nil, fmt.Errorf("%w: it is issued in the future", ErrIdToken)
+	}
+	// a token for several audiences names the one it was handed to
+	if azp, ok := claims["azp"].(string); ok && azp != p.config.ClientId {
+		return nil, fmt.Errorf("%w: it is handed to someone else", ErrIdToken)
+	}
+	given, _ := claims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(given), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: the nonce does not match", ErrIdToken)
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: it names no subject", ErrIdToken)
+	}
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+	return &Claims{
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: isTrue(claims["email_verified"]),
+		Name:          name,
+		Roles:         listOf(claims[p.config.RoleClaim]),
+	}, nil
+}
+
+// NewSecret returns a random value for the state, the nonce or the PKCE verifier.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// challenge is the S256 PKCE challenge of the verifier.
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// key returns the signing key with the id. Keys the provider has rotated in since are fetched again.
+func (p *Provider) key(kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	keys, err := p.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	// a provider with a single key may leave the id out
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *Provider) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	set := struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}{}
+	if err := getJSON(p.client, p.metadata.JwksUri, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+func getJSON(client *http.Client, address string, v interface{}) error {
+	res, err := client.Get(address)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %s", address, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// isTrue reads a boolean claim; some providers send it as a string.
+func isTrue(claim interface{}) bool {
+	switch v := claim.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// listOf reads a claim that is a string or an array of them.
+func listOf(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
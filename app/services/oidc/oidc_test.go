@@ -0,0 +1,327 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	clientId     = "task-sync"
+	clientSecret = "secret"
+	redirectUrl  = "https://tasks.example.com/login/oidc/callback"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// grant is what the mock provider remembers about an authorization until the code is exchanged.
+type grant struct {
+	challenge   string
+	nonce       string
+	redirectUrl string
+}
+
+// mockProvider is an identity provider that signs in whoever asks, as the user in claims.
+type mockProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+	claims jwt.MapClaims
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	m := &mockProvider{key: newKey(t), kid: "key-1", grants: map[string]grant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, Metadata{
+			Issuer:                m.server.URL,
+			AuthorizationEndpoint: m.server.URL + "/authorize",
+			TokenEndpoint:         m.server.URL + "/token",
+			JwksUri:               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/authorize", m.authorize)
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		writeJSON(w, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"kid": m.kid,
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	m.claims = jwt.MapClaims{
+		"sub":            "user-1",
+		"email":          "ada@school.example",
+		"email_verified": true,
+		"name":           "Ada",
+		"roles":          []string{"teacher", "parent"},
+	}
+	return m
+}
+
+// authorize signs the user in at once and sends them back with a code.
+func (m *mockProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != clientId || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	code, _ := NewSecret()
+	m.mu.Lock()
+	m.grants[code] = grant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce"), redirectUrl: query.Get("redirect_uri")}
+	m.mu.Unlock()
+	back, _ := url.Parse(query.Get("redirect_uri"))
+	back.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+// token exchanges a code once, for the client that proves it started the authorization.
+func (m *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != clientId || secret != clientSecret {
+		http.Error(w, "invalid_client", http.StatusUnauthorized)
+		return
+	}
+	m.mu.Lock()
+	g, ok := m.grants[r.PostFormValue("code")]
+	delete(m.grants, r.PostFormValue("code"))
+	m.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != g.redirectUrl ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		http.Error(w, "invalid_grant", http.StatusBadRequest)
+		return
+	}
+	claims := jwt.MapClaims{"iss": m.server.URL, "aud": clientId, "nonce": g.nonce, "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()}
+	for name, value := range m.claims {
+		claims[name] = value
+	}
+	writeJSON(w, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": m.sign(claims)})
+}
+
+func (m *mockProvider) sign(claims jwt.MapClaims) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// rotate makes the provider sign with a new key.
+func (m *mockProvider) rotate(t *testing.T, kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.key = newKey(t)
+	m.kid = kid
+}
+
+// idToken is a token the provider signs for the test itself, valid unless claims say otherwise.
+func (m *mockProvider) idToken(claims jwt.MapClaims) string {
+	token := jwt.MapClaims{"iss": m.server.URL, "aud": clientId, "sub": "user-1", "nonce": "nonce", "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()}
+	for name, value := range claims {
+		token[name] = value
+	}
+	return m.sign(token)
+}
+
+func (m *mockProvider) discover(t *testing.T) *Provider {
+	t.Helper()
+	provider, err := Discover(Config{
+		Issuer:       m.server.URL,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		Scopes:       []string{"email", "profile"},
+		RoleClaim:    "roles",
+	}, m.server.Client())
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	return provider.WithClock(func() time.Time { return now })
+}
+
+// signIn follows the provider's sign in page and returns the code and state it sends back.
+func (m *mockProvider) signIn(t *testing.T, address string) (string, string) {
+	t.Helper()
+	client := *m.server.Client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	res, err := client.Get(address)
+	if err != nil {
+		t.Fatalf("sign in page: %v", err)
+	}
+	res.Body.Close()
+	back, err := url.Parse(res.Header.Get("Location"))
+	if err != nil || res.StatusCode != http.StatusFound {
+		t.Fatalf("sign in page answered %s", res.Status)
+	}
+	return back.Query().Get("code"), back.Query().Get("state")
+}
+
+func TestSignIn(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.discover(t)
+	code, state := m.signIn(t, provider.AuthCodeURL(redirectUrl, "state", "nonce", "verifier-of-the-attempt"))
+	if state != "state" {
+		t.Fatalf("state: got %q, want %q", state, "state")
+	}
+	claims, err := provider.Exchange(redirectUrl, code, "verifier-of-the-attempt", "nonce")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "ada@school.example" || !claims.EmailVerified || claims.Name != "Ada" {
+		t.Errorf("claims: got %+v", claims)
+	}
+	if len(claims.Roles) != 2 || claims.Roles[0] != "teacher" || claims.Roles[1] != "parent" {
+		t.Errorf("roles: got %v, want [teacher parent]", claims.Roles)
+	}
+	if _, err := provider.Exchange(redirectUrl, code, "verifier-of-the-attempt", "nonce"); !errors.Is(err, ErrExchange) {
+		t.Errorf("exchanging the code twice: got %v, want %v", err, ErrExchange)
+	}
+}
+
+func TestExchangeNeedsVerifier(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.discover(t)
+	code, _ := m.signIn(t, provider.AuthCodeURL(redirectUrl, "state", "nonce", "verifier-of-the-attempt"))
+	if _, err := provider.Exchange(redirectUrl, code, "someone-elses-verifier", "nonce"); !errors.Is(err, ErrExchange) {
+		t.Errorf("got %v, want %v", err, ErrExchange)
+	}
+}
+
+func TestExchangeNeedsNonce(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.discover(t)
+	code, _ := m.signIn(t, provider.AuthCodeURL(redirectUrl, "state", "nonce", "verifier-of-the-attempt"))
+	if _, err := provider.Exchange(redirectUrl, code, "verifier-of-the-attempt", "another-nonce"); !errors.Is(err, ErrIdToken) {
+		t.Errorf("got %v, want %v", err, ErrIdToken)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.discover(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"iss": m.server.URL, "aud": clientId, "sub": "user-1", "nonce": "nonce", "exp": now.Add(time.Hour).Unix()})
+	foreign.Header["kid"] = m.kid
+	signedByOther, _ := foreign.SignedString(other)
+	symmetric, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": m.server.URL, "aud": clientId, "sub": "user-1", "nonce": "nonce", "exp": now.Add(time.Hour).Unix()}).SignedString([]byte(clientSecret))
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", m.idToken(nil), nil},
+		{"for several audiences", m.idToken(jwt.MapClaims{"aud": []string{clientId, "other"}, "azp": clientId}), nil},
+		{"handed to another client", m.idToken(jwt.MapClaims{"aud": []string{clientId, "other"}, "azp": "other"}), ErrIdToken},
+		{"for another client", m.idToken(jwt.MapClaims{"aud": "other"}), ErrIdToken},
+		{"from another issuer", m.idToken(jwt.MapClaims{"iss": "https://evil.example"}), ErrIdToken},
+		{"expired", m.idToken(jwt.MapClaims{"exp": now.Add(-2 * Leeway).Unix()}), ErrIdToken},
+		{"expired within the leeway", m.idToken(jwt.MapClaims{"exp": now.Add(-Leeway / 2).Unix()}), nil},
+		{"issued in the future", m.idToken(jwt.MapClaims{"iat": now.Add(2 * Leeway).Unix()}), ErrIdToken},
+		{"with another nonce", m.idToken(jwt.MapClaims{"nonce": "other"}), ErrIdToken},
+		{"without a subject", m.idToken(jwt.MapClaims{"sub": ""}), ErrIdToken},
+		{"signed with another key", signedByOther, ErrIdToken},
+		{"signed with the client secret", symmetric, ErrIdToken},
+		{"malformed", "not.a.token", ErrIdToken},
+	}
+	for _, tt := range tests {
+		_, err := provider.Verify(tt.token, "nonce")
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestClaims(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.discover(t)
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		verified bool
+		roles    []string
+	}{
+		{"roles as a string", jwt.MapClaims{"roles": "student", "email_verified": true}, true, []string{"student"}},
+		{"verified as a string", jwt.MapClaims{"email_verified": "true"}, true, nil},
+		{"unverified", jwt.MapClaims{"email_verified": false}, false, nil},
+		{"without verification", jwt.MapClaims{}, false, nil},
+	}
+	for _, tt := range tests {
+		claims, err := provider.Verify(m.idToken(tt.claims), "nonce")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if claims.EmailVerified != tt.verified {
+			t.Errorf("%s: verified got %v, want %v", tt.name, claims.EmailVerified, tt.verified)
+		}
+		if len(claims.Roles) != len(tt.roles) || len(tt.roles) > 0 && claims.Roles[0] != tt.roles[0] {
+			t.Errorf("%s: roles got %v, want %v", tt.name, claims.Roles, tt.roles)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.discover(t)
+	if _, err := provider.Verify(m.idToken(nil), "nonce"); err != nil {
+		t.Fatalf("before rotation: %v", err)
+	}
+	m.rotate(t, "key-2")
+	if _, err := provider.Verify(m.idToken(nil), "nonce"); err != nil {
+		t.Errorf("after rotation: %v", err)
+	}
+}
+
+func TestDiscoverChecksIssuer(t *testing.T) {
+	m := newMockProvider(t)
+	// the document names the issuer without the trailing slash
+	_, err := Discover(Config{Issuer: m.server.URL + "/", ClientId: clientId}, m.server.Client())
+	if !errors.Is(err, ErrDiscovery) {
+		t.Errorf("got %v, want %v", err, ErrDiscovery)
+	}
+}
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,224 @@
+package policy
+
+import (
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+)
+
+// Every check answers the same way: a resource that has nothing to do with the
+// actor is not found, so ids of other accounts' data do not leak, and an action
+// the actor's role cannot take on a resource it can see is forbidden.
+
+// Action is what the actor wants to do with a resource.
+type Action string
+
+const (
+	View    Action = "view"
+	Create  Action = "create"
+	Update  Action = "update"
+	Delete  Action = "delete"
+	Comment Action = "comment"
+)
+
+// Actor is the signed in teacher, student or admin asking for access. Id is the teacher, student or admin
+// the actor acts as, AccountId the user or admin who signed in.
+type Actor interface {
+	IsTeacher() bool
+	IsAdmin() bool
+	Id() uint
+	AccountId() uint
+}
+
+// TeacherOnly allows the features only teachers have, like giving homework or managing groups.
+func TeacherOnly(actor Actor) error {
+	if !actor.IsTeacher() {
+		return failure.ErrForbidden
+	}
+	return nil
+}
+
+// StudentOnly allows the features only students have, like choosing a teacher.
+func StudentOnly(actor Actor) error {
+	if !isStudent(actor) {
+		return failure.ErrForbidden
+	}
+	return nil
+}
+
+// AdminOnly allows the console admins manage accounts in.
+func AdminOnly(actor Actor) error {
+	if !actor.IsAdmin() {
+		return failure.ErrForbidden
+	}
+	return nil
+}
+
+// Homework lets its teacher and its student work on it. Only the teacher deletes it. Admins may look at any homework.
+func Homework(actor Actor, action Action, homework *models.Homework) error {
+	if actor.IsAdmin() {
+		return adminViews(action)
+	}
+	givesIt := actor.IsTeacher() && homework.TeacherId == actor.Id()
+	doesIt := isStudent(actor) && homework.StudentId == actor.Id()
+	if !givesIt && !doesIt {
+		return failure.ErrNotFound
+	}
+	switch action {
+	case View, Update, Comment:
+		return nil
+	case Delete:
+		if givesIt {
+			return nil
+		}
+	}
+	return failure.ErrForbidden
+}
+
+// Student lets teachers view the students they teach or taught and manage the ones they teach now,
+// and students their own profile. Admins may look at any student. The student's enrollments have to be loaded.
+func Student(actor Actor, action Action, student *models.Student) error {
+	if actor.IsAdmin() {
+		return adminViews(action)
+	}
+	if actor.IsTeacher() {
+		enrollment := student.EnrollmentWith(actor.Id())
+		if enrollment == nil {
+			return failure.ErrNotFound
+		}
+		if action == View || action == Update && enrollment.Active() {
+			return nil
+		}
+		return failure.ErrForbidden
+	}
+	if student.Id != actor.Id() {
+		return failure.ErrNotFound
+	}
+	return nil
+}
+
+// Enrollment is managed by the teacher and seen by the student. Admins move students between teachers.
+func Enrollment(actor Actor, action Action, enrollment *models.Enrollment) error {
+	if actor.IsAdmin() {
+		if action == View || action == Update {
+			return nil
+		}
+		return failure.ErrForbidden
+	}
+	if actor.IsTeacher() {
+		if enrollment.TeacherId != actor.Id() {
+			return failure.ErrNotFound
+		}
+		if action == View || action == Update {
+			return nil
+		}
+		return failure.ErrForbidden
+	}
+	if enrollment.StudentId != actor.Id() {
+		return failure.ErrNotFound
+	}
+	if action == View {
+		return nil
+	}
+	return failure.ErrForbidden
+}
+
+// JoinRequest is answered by the teacher it was sent to and seen by the student who sent it.
+func JoinRequest(actor Actor, action Action, request *models.JoinRequest) error {
+	if actor.IsAdmin() {
+		return failure.ErrForbidden
+	}
+	if actor.IsTeacher() {
+		if request.TeacherId != actor.Id() {
+			return failure.ErrNotFound
+		}
+		if action == View || action == Update {
+			return nil
+		}
+		return failure.ErrForbidden
+	}
+	if request.StudentId != actor.Id() {
+		return failure.ErrNotFound
+	}
+	if action == View {
+		return nil
+	}
+	return failure.ErrForbidden
+}
+
+// Group is managed by the teacher who created it.
+func Group(actor Actor, action Action, group *models.Group) error {
+	return teacherOwned(actor, action, group.TeacherId)
+}
+
+// Rubric is managed by the teacher who created it.
+func Rubric(actor Actor, action Action, rubric *models.Rubric) error {
+	return teacherOwned(actor, action, rubric.TeacherId)
+}
+
+// Template is managed by the teacher who created it.
+func Template(actor Actor, action Action, template *models.HomeworkTemplate) error {
+	return teacherOwned(actor, action, template.TeacherId)
+}
+
+// HomeworkType is managed by the teacher who created it. Global types can be used by every teacher but changed by none.
+func HomeworkType(actor Actor, action Action, homeworkType *models.HomeworkType) error {
+	if homeworkType.TeacherId != nil {
+		return teacherOwned(actor, action, *homeworkType.TeacherId)
+	}
+	if err := TeacherOnly(actor); err != nil {
+		return err
+	}
+	if action != View {
+		return failure.ErrForbidden
+	}
+	return nil
+}
+
+// Session lets every account see and sign out its own devices, whatever role they act in there, admins included.
+func Session(actor Actor, action Action, session *models.Session) error {
+	if (session.Role == "admin") != actor.IsAdmin() || session.AccountId != actor.AccountId() {
+		return failure.ErrNotFound
+	}
+	if action == View || action == Delete {
+		return nil
+	}
+	return failure.ErrForbidden
+}
+
+// ApiToken lets users see and revoke their own personal access tokens, whatever role the tokens act in.
+// Admins have none.
+func ApiToken(actor Actor, action Action, token *models.ApiToken) error {
+	if actor.IsAdmin() || token.UserId != actor.AccountId() {
+		return failure.ErrNotFound
+	}
+	if action == View || action == Delete {
+		return nil
+	}
+	return failure.ErrForbidden
+}
+
+func teacherOwned(actor Actor, action Action, teacherId uint) error {
+	if err := TeacherOnly(actor); err != nil {
+		return err
+	}
+	if teacherId != actor.Id() {
+		return failure.ErrNotFound
+	}
+	switch action {
+	case View, Create, Update, Delete:
+		return nil
+	}
+	return failure.ErrForbidden
+}
+
+func isStudent(actor Actor) bool {
+	return !actor.IsTeacher() && !actor.IsAdmin()
+}
+
+// adminViews lets admins look at a resource without changing it.
+func adminViews(action Action) error {
+	if action == View {
+		return nil
+	}
+	return failure.ErrForbidden
+}
@@ -0,0 +1,286 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MikhailR1337/task-sync-x/app/infrastructure/models"
+	"github.com/MikhailR1337/task-sync-x/app/services/failure"
+)
+
+type actor struct {
+	teacher bool
+	admin   bool
+	id      uint
+	account uint
+}
+
+func (a actor) IsTeacher() bool {
+	return a.teacher
+}
+
+func (a actor) IsAdmin() bool {
+	return a.admin
+}
+
+func (a actor) Id() uint {
+	return a.id
+}
+
+func (a actor) AccountId() uint {
+	return a.account
+}
+
+var (
+	owner        = actor{teacher: true, id: 1, account: 1}
+	otherTeacher = actor{teacher: true, id: 2, account: 2}
+	// formerTeacher taught the assignee before.
+	formerTeacher = actor{teacher: true, id: 3, account: 3}
+	assignee      = actor{id: 10, account: 10}
+	otherStudent  = actor{id: 11, account: 11}
+	// sameIdTeacher shares the id of the assignee, so the role has to tell them apart.
+	sameIdTeacher = actor{teacher: true, id: 10, account: 12}
+	// sameIdStudent shares the id of the owner.
+	sameIdStudent = actor{id: 1, account: 13}
+	// ownerStudying is the owner's user acting as a student.
+	ownerStudying = actor{id: 20, account: 1}
+	// admin shares the id and the account id of the owner too.
+	admin = actor{admin: true, id: 1, account: 1}
+
+	actions = []Action{View, Create, Update, Delete, Comment}
+)
+
+// outcome is what a check returns for every action; actions left out are allowed.
+type outcome map[Action]error
+
+func all(err error) outcome {
+	o := outcome{}
+	for _, action := range actions {
+		o[action] = err
+	}
+	return o
+}
+
+func except(err error, allowed ...Action) outcome {
+	o := all(err)
+	for _, action := range allowed {
+		delete(o, action)
+	}
+	return o
+}
+
+type testCase struct {
+	name  string
+	actor Actor
+	want  outcome
+}
+
+func run(t *testing.T, tests []testCase, check func(Actor, Action) error) {
+	t.Helper()
+	for _, tt := range tests {
+		for _, action := range actions {
+			err := check(tt.actor, action)
+			want := tt.want[action]
+			if want == nil && err != nil || want != nil && !errors.Is(err, want) {
+				t.Errorf("%s %s: got %v, want %v", tt.name, action, err, want)
+			}
+		}
+	}
+}
+
+func TestTeacherOnly(t *testing.T) {
+	tests := []struct {
+		actor Actor
+		want  error
+	}{
+		{owner, nil},
+		{assignee, failure.ErrForbidden},
+		{admin, failure.ErrForbidden},
+	}
+	for _, tt := range tests {
+		if err := TeacherOnly(tt.actor); !errors.Is(err, tt.want) {
+			t.Errorf("TeacherOnly(%+v): got %v, want %v", tt.actor, err, tt.want)
+		}
+	}
+}
+
+func TestStudentOnly(t *testing.T) {
+	tests := []struct {
+		actor Actor
+		want  error
+	}{
+		{assignee, nil},
+		{owner, failure.ErrForbidden},
+		{admin, failure.ErrForbidden},
+	}
+	for _, tt := range tests {
+		if err := StudentOnly(tt.actor); !errors.Is(err, tt.want) {
+			t.Errorf("StudentOnly(%+v): got %v, want %v", tt.actor, err, tt.want)
+		}
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	tests := []struct {
+		actor Actor
+		want  error
+	}{
+		{admin, nil},
+		{owner, failure.ErrForbidden},
+		{assignee, failure.ErrForbidden},
+	}
+	for _, tt := range tests {
+		if err := AdminOnly(tt.actor); !errors.Is(err, tt.want) {
+			t.Errorf("AdminOnly(%+v): got %v, want %v", tt.actor, err, tt.want)
+		}
+	}
+}
+
+func TestHomework(t *testing.T) {
+	homework := &models.Homework{TeacherId: owner.id, StudentId: assignee.id}
+	tests := []testCase{
+		{"owner", owner, except(failure.ErrForbidden, View, Update, Comment, Delete)},
+		{"assignee", assignee, except(failure.ErrForbidden, View, Update, Comment)},
+		{"other teacher", otherTeacher, all(failure.ErrNotFound)},
+		{"other student", otherStudent, all(failure.ErrNotFound)},
+		{"teacher with the assignee's id", sameIdTeacher, all(failure.ErrNotFound)},
+		{"student with the owner's id", sameIdStudent, all(failure.ErrNotFound)},
+		{"admin", admin, except(failure.ErrForbidden, View)},
+	}
+	run(t, tests, func(actor Actor, action Action) error {
+		return Homework(actor, action, homework)
+	})
+}
+
+func TestStudent(t *testing.T) {
+	student := &models.Student{Id: assignee.id, Enrollments: []models.Enrollment{
+		{StudentId: assignee.id, TeacherId: owner.id, Status: models.EnrollmentActive},
+		{StudentId: assignee.id, TeacherId: formerTeacher.id, Status: models.EnrollmentFinished},
+	}}
+	tests := []testCase{
+		{"their teacher", owner, except(failure.ErrForbidden, View, Update)},
+		{"former teacher", formerTeacher, except(failure.ErrForbidden, View)},
+		{"other teacher", otherTeacher, all(failure.ErrNotFound)},
+		{"teacher with the student's id", sameIdTeacher, all(failure.ErrNotFound)},
+		{"themselves", assignee, outcome{}},
+		{"other student", otherStudent, all(failure.ErrNotFound)},
+		{"student with the teacher's id", sameIdStudent, all(failure.ErrNotFound)},
+		{"admin", admin, except(failure.ErrForbidden, View)},
+	}
+	run(t, tests, func(actor Actor, action Action) error {
+		return Student(actor, action, student)
+	})
+}
+
+func TestEnrollment(t *testing.T) {
+	enrollment := &models.Enrollment{StudentId: assignee.id, TeacherId: owner.id}
+	tests := []testCase{
+		{"teacher", owner, except(failure.ErrForbidden, View, Update)},
+		{"other teacher", otherTeacher, all(failure.ErrNotFound)},
+		{"teacher with the student's id", sameIdTeacher, all(failure.ErrNotFound)},
+		{"student", assignee, except(failure.ErrForbidden, View)},
+		{"other student", otherStudent, all(failure.ErrNotFound)},
+		{"student with the teacher's id", sameIdStudent, all(failure.ErrNotFound)},
+		{"admin", admin, except(failure.ErrForbidden, View, Update)},
+	}
+	run(t, tests, func(actor Actor, action Action) error {
+		return Enrollment(actor, action, enrollment)
+	})
+}
+
+func TestJoinRequest(t *testing.T) {
+	request := &models.JoinRequest{StudentId: assignee.id, TeacherId: owner.id}
+	tests := []testCase{
+		{"asked teacher", owner, except(failure.ErrForbidden, View, Update)},
+		{"other teacher", otherTeacher, all(failure.ErrNotFound)},
+		{"teacher with the student's id", sameIdTeacher, all(failure.ErrNotFound)},
+		{"asking student", assignee, except(failure.ErrForbidden, View)},
+		{"other student", otherStudent, all(failure.ErrNotFound)},
+		{"student with the teacher's id", sameIdStudent, all(failure.ErrNotFound)},
+		{"admin", admin, all(failure.ErrForbidden)},
+	}
+	run(t, tests, func(actor Actor, action Action) error {
+		return JoinRequest(actor, action, request)
+	})
+}
+
+func TestSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *models.Session
+		actor   Actor
+		want    outcome
+	}{
+		{"teacher's own", &models.Session{Role: "teacher", AccountId: owner.account}, owner, except(failure.ErrForbidden, View, Delete)},
+		{"teacher's seen by the same user studying", &models.Session{Role: "teacher", AccountId: owner.account}, ownerStudying, except(failure.ErrForbidden, View, Delete)},
+		{"student's seen by the same user teaching", &models.Session{Role: "student", AccountId: owner.account}, owner, except(failure.ErrForbidden, View, Delete)},
+		{"teacher's of another teacher", &models.Session{Role: "teacher", AccountId: owner.account}, otherTeacher, all(failure.ErrNotFound)},
+		{"teacher's seen by a student with the same id", &models.Session{Role: "teacher", AccountId: owner.account}, sameIdStudent, all(failure.ErrNotFound)},
+		{"teacher's seen by an admin with the same id", &models.Session{Role: "teacher", AccountId: owner.account}, admin, all(failure.ErrNotFound)},
+		{"student's own", &models.Session{Role: "student", AccountId: assignee.account}, assignee, except(failure.ErrForbidden, View, Delete)},
+		{"student's seen by a teacher with the same id", &models.Session{Role: "student", AccountId: assignee.account}, sameIdTeacher, all(failure.ErrNotFound)},
+		{"admin's own", &models.Session{Role: "admin", AccountId: admin.account}, admin, except(failure.ErrForbidden, View, Delete)},
+		{"admin's seen by a user with the same id", &models.Session{Role: "admin", AccountId: admin.account}, owner, all(failure.ErrNotFound)},
+	}
+	for _, tt := range tests {
+		run(t, []testCase{{tt.name, tt.actor, tt.want}}, func(actor Actor, action Action) error {
+			return Session(actor, action, tt.session)
+		})
+	}
+}
+
+func TestApiToken(t *testing.T) {
+	token := &models.ApiToken{Role: "teacher", UserId: owner.account}
+	tests := []testCase{
+		{"user's own", owner, except(failure.ErrForbidden, View, Delete)},
+		{"user's own acting in the other role", ownerStudying, except(failure.ErrForbidden, View, Delete)},
+		{"another user's", otherTeacher, all(failure.ErrNotFound)},
+		{"seen by a student with the same id", sameIdStudent, all(failure.ErrNotFound)},
+		{"seen by an admin with the same id", admin, all(failure.ErrNotFound)},
+	}
+	run(t, tests, func(actor Actor, action Action) error {
+		return ApiToken(actor, action, token)
+	})
+}
+
+func TestTeacherOwned(t *testing.T) {
+	tests := []testCase{
+		{"owner", owner, except(failure.ErrForbidden, View, Create, Update, Delete)},
+		{"other teacher", otherTeacher, all(failure.ErrNotFound)},
+		{"student", assignee, all(failure.ErrForbidden)},
+		{"student with the owner's id", sameIdStudent, all(failure.ErrForbidden)},
+		{"admin", admin, all(failure.ErrForbidden)},
+	}
+	checks := map[string]func(Actor, Action) error{
+		"Group": func(actor Actor, action Action) error {
+			return Group(actor, action, &models.Group{TeacherId: owner.id})
+		},
+		"Rubric": func(actor Actor, action Action) error {
+			return Rubric(actor, action, &models.Rubric{TeacherId: owner.id})
+		},
+		"Template": func(actor Actor, action Action) error {
+			return Template(actor, action, &models.HomeworkTemplate{TeacherId: owner.id})
+		},
+		"HomeworkType": func(actor Actor, action Action) error {
+			return HomeworkType(actor, action, &models.HomeworkType{TeacherId: &owner.id})
+		},
+	}
+	for name, check := range checks {
+		t.Run(name, func(t *testing.T) {
+			run(t, tests, check)
+		})
+	}
+}
+
+func TestGlobalHomeworkType(t *testing.T) {
+	homeworkType := &models.HomeworkType{}
+	tests := []testCase{
+		{"teacher", owner, except(failure.ErrForbidden, View)},
+		{"other teacher", otherTeacher, except(failure.ErrForbidden, View)},
+		{"student", assignee, all(failure.ErrForbidden)},
+		{"admin", admin, all(failure.ErrForbidden)},
+	}
+	run(t, tests, func(actor Actor, action Action) error {
+		return HomeworkType(actor, action, homeworkType)
+	})
+}
@@ -0,0 +1,109 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// secret is the key of the RFC 6238 test vectors, "12345678901234567890", in base32.
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeVectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	key, err := decode(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		at := time.Unix(tt.unix, 0)
+		if got := code(key, Step(at), 8); got != tt.want {
+			t.Errorf("code at %d: got %s, want %s", tt.unix, got, tt.want)
+		}
+		got, err := Code(secret, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := tt.want[len(tt.want)-Digits:]; got != want {
+			t.Errorf("Code at %d: got %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"current period", now, true},
+		{"previous period", now.Add(-Period), true},
+		{"next period", now.Add(Period), true},
+		{"two periods ago", now.Add(-2 * Period), false},
+		{"two periods ahead", now.Add(2 * Period), false},
+	}
+	for _, tt := range tests {
+		given, err := Code(secret, tt.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, ok := Validate(secret, given, now)
+		if ok != tt.ok {
+			t.Errorf("%s: got %v, want %v", tt.name, ok, tt.ok)
+		}
+		if ok && step != Step(tt.at) {
+			t.Errorf("%s: matched step %d, want %d", tt.name, step, Step(tt.at))
+		}
+	}
+	for _, given := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := Validate(secret, given, now); ok {
+			t.Errorf("Validate(%q): got true, want false", given)
+		}
+	}
+	if _, ok := Validate("not base32!", "123456", now); ok {
+		t.Error("Validate with a broken secret: got true, want false")
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Error("NewSecret returned the same secret twice")
+	}
+	if _, err := Code(first, time.Now()); err != nil {
+		t.Errorf("Code with a new secret: %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	got := URI("Task Sync", "teacher@example.com", secret)
+	for _, part := range []string{
+		"otpauth://totp/Task%20Sync:teacher@example.com?",
+		"secret=" + secret,
+		"issuer=Task+Sync",
+		"digits=6",
+		"period=30",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("URI %s does not contain %s", got, part)
+		}
+	}
+}